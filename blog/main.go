@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/NBjjp/JpWebFrame"
@@ -89,8 +90,8 @@ func main() {
 	order.Any("/gets", func(ctx *frame.Context) {
 		fmt.Fprintln(ctx.W, "路由分组测试")
 	})
-	order.Get("/getss/:id", func(ctx *frame.Context) {
-		fmt.Fprintln(ctx.W, "id路由分组测试")
+	order.Get("/getss/:id<int>", func(ctx *frame.Context) {
+		fmt.Fprintln(ctx.W, "id路由分组测试", ctx.Param("id"))
 	})
 	//提前将模板加载到内存当中
 	engine.LoadTemplate("tpl/*.html")
@@ -103,7 +104,7 @@ func main() {
 		if err != nil {
 			fmt.Println(err)
 		}
-	})
+	}).Name("user.testtemplate")
 
 	//测试输出JSON格式的模板
 	g.Get("/json", func(ctx *frame.Context) {
@@ -143,7 +144,8 @@ func main() {
 
 	//重定向
 	g.Get("/re", func(ctx *frame.Context) {
-		ctx.Redirect(http.StatusFound, "/user/testtemplate")
+		url, _ := engine.URL("user.testtemplate")
+		ctx.Redirect(http.StatusFound, url)
 	})
 	//测试string
 	g.Get("/string", func(ctx *frame.Context) {
@@ -268,6 +270,10 @@ func main() {
 	})
 	//测试协程池
 	p, _ := jppool.NewPool(2)
+	engine.OnShutdown(func(ctx context.Context) error {
+		p.Release()
+		return nil
+	})
 	g.Post("/pool", func(ctx *frame.Context) {
 		currentTime := time.Now().UnixMilli()
 		var wg sync.WaitGroup
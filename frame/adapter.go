@@ -0,0 +1,74 @@
+package frame
+
+import (
+	jplog "github.com/NBjjp/JpWebFrame/log"
+	"net/http"
+	"net/url"
+)
+
+//将标准库的http.Handler转化为HandlerFunc
+//g.Get("/metrics", frame.WrapH(promhttp.Handler()))
+func WrapH(h http.Handler) HandlerFunc {
+	return func(ctx *Context) {
+		h.ServeHTTP(ctx.W, ctx.R)
+	}
+}
+
+//将标准库的http.HandlerFunc转化为HandlerFunc
+//g.Get("/debug/pprof/", frame.WrapF(pprof.Index))
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return func(ctx *Context) {
+		f(ctx.W, ctx.R)
+	}
+}
+
+//将标准库形式的中间件转化为MiddlewareFunc
+//中间件替换的ResponseWriter和Request会传给后续的处理器
+func WrapMiddleware(m func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx.W = w
+				ctx.R = r
+				next(ctx)
+			})).ServeHTTP(ctx.W, ctx.R)
+		}
+	}
+}
+
+//将MiddlewareFunc转化为标准库形式的中间件  用于http.ServeMux等其他框架
+//此时Context中没有Engine，不能使用Template等依赖Engine的方法
+func HTTPMiddleware(m MiddlewareFunc) func(http.Handler) http.Handler {
+	logger := jplog.Default()
+	return func(next http.Handler) http.Handler {
+		h := m(func(ctx *Context) {
+			next.ServeHTTP(ctx.W, ctx.R)
+		})
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := &Context{R: r, Logger: logger}
+			ctx.writermem.reset(w)
+			ctx.Writer = &ctx.writermem
+			ctx.W = ctx.Writer
+			h(ctx)
+			ctx.Writer.WriteHeaderNow()
+		})
+	}
+}
+
+//将http.Handler挂载到prefix下  prefix及其下的所有路径、所有请求方式都交给handler处理
+//handler收到的请求路径去掉了prefix   g.Mount("/debug", mux) 访问/user/debug/vars  mux收到/vars
+//*Engine同样实现了http.Handler，可以挂载子Engine
+func (r *routerGroup) Mount(prefix string, handler http.Handler, middlewareFunc ...MiddlewareFunc) {
+	h := func(ctx *Context) {
+		req := new(http.Request)
+		*req = *ctx.R
+		u := new(url.URL)
+		*u = *ctx.R.URL
+		u.Path = "/" + ctx.CatchAll()
+		u.RawPath = ""
+		req.URL = u
+		handler.ServeHTTP(ctx.W, req)
+	}
+	r.Any(prefix, h, middlewareFunc...)
+	r.Any(joinPaths(prefix, catchAllKey), h, middlewareFunc...)
+}
@@ -0,0 +1,79 @@
+package frame
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterGroupMount(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vars", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "vars %s", r.URL.RawQuery)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "root %s", r.URL.Path)
+	})
+	sub := New()
+	sub.Group("").Get("/ping", func(ctx *Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+
+	engine := New()
+	g := engine.Group("user")
+	g.Mount("/debug", mux)
+	g.Mount("/sub", sub)
+	g.Get("/wrap", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "wrapped")
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodGet, "/user/debug/vars?a=1", "vars a=1"},
+		{http.MethodPost, "/user/debug/x/y", "root /x/y"},
+		{http.MethodGet, "/user/debug", "root /"},
+		{http.MethodGet, "/user/sub/ping", "pong"},
+		{http.MethodGet, "/user/wrap", "wrapped"},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, tt.method, tt.path)
+		if w.Body.String() != tt.body {
+			t.Errorf("%s %s: body = %q, want %q", tt.method, tt.path, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestMiddlewareAdapters(t *testing.T) {
+	header := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r)
+		})
+	}
+	engine := New()
+	engine.Group("user").Get("/std", func(ctx *Context) {}, WrapMiddleware(header))
+	if w := performRequest(engine, http.MethodGet, "/user/std"); w.Header().Get("X-Std") != "1" {
+		t.Error("WrapMiddleware: header not set")
+	}
+
+	called := false
+	mw := func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			called = true
+			ctx.W.Header().Set("X-Frame", "1")
+			next(ctx)
+		}
+	}
+	h := HTTPMiddleware(mw)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called || w.Header().Get("X-Frame") != "1" || w.Body.String() != "ok" {
+		t.Errorf("HTTPMiddleware: called = %v, header = %q, body = %q", called, w.Header().Get("X-Frame"), w.Body.String())
+	}
+}
@@ -38,8 +38,9 @@ func (a *Accounts) BasicAuth(next HandlerFunc) HandlerFunc {
 func (a *Accounts) UnAuthHandlers(ctx *Context) {
 	if a.UnAuthHandler != nil {
 		a.UnAuthHandler(ctx)
+		ctx.Abort()
 	} else {
-		ctx.W.WriteHeader(http.StatusUnauthorized)
+		ctx.AbortWithStatus(http.StatusUnauthorized)
 	}
 }
 func BasicAuth(username, password string) string {
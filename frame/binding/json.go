@@ -122,7 +122,7 @@ func checkParam(value reflect.Value, data any, decoder *json.Decoder) error {
 		}
 		value := mapData[name]
 		if value == nil && mustType == "mustType" {
-			return errors.New(fmt.Sprintf("filed [%s] is required,because [%s] is must", tag))
+			return errors.New(fmt.Sprintf("filed [%s] is required,because [%s] is must", tag, tag))
 		}
 	}
 	if data != nil {
@@ -5,6 +5,7 @@ import (
 	"github.com/BurntSushi/toml"
 	jpLog "github.com/NBjjp/JpWebFrame/log"
 	"os"
+	"strings"
 )
 
 var Conf = &JpConfig{
@@ -13,6 +14,7 @@ var Conf = &JpConfig{
 	Template: make(map[string]any),
 	Db:       make(map[string]any),
 	Pool:     make(map[string]any),
+	Server:   make(map[string]any),
 }
 
 type JpConfig struct {
@@ -21,6 +23,7 @@ type JpConfig struct {
 	Template map[string]any
 	Db       map[string]any
 	Pool     map[string]any
+	Server   map[string]any
 }
 
 func init() {
@@ -28,7 +31,8 @@ func init() {
 }
 func loadToml() {
 	confFile := flag.String("conf", "conf/app.toml", "app config file")
-	flag.Parse()
+	//init中调用flag.Parse会使go test等携带其他参数的程序直接退出，这里只解析-conf
+	*confFile = lookupConfArg(os.Args[1:], *confFile)
 	if _, err := os.Stat(*confFile); err != nil {
 		Conf.logger.Info("conf/app.toml file not load，because not exist")
 		return
@@ -40,3 +44,18 @@ func loadToml() {
 		return
 	}
 }
+
+//从命令行参数中查找 -conf 的值，支持 -conf path 和 -conf=path 两种写法
+func lookupConfArg(args []string, def string) string {
+	for i := 0; i < len(args); i++ {
+		arg := strings.TrimPrefix(args[i], "-")
+		arg = strings.TrimPrefix(arg, "-")
+		if arg == "conf" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, "conf=") {
+			return arg[len("conf="):]
+		}
+	}
+	return def
+}
@@ -0,0 +1,80 @@
+package frame
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//路由参数约束   :id<int>  :slug<[a-z-]+>  :uuid<uuid>
+//不满足约束的请求继续匹配其他路由，都不满足时返回404
+type paramConstraint struct {
+	//约束表达式  int uuid 或正则
+	expr  string
+	match func(value string) bool
+	//将参数转化为对应类型  为nil时保持字符串
+	convert func(key, value string) (any, error)
+}
+
+//内置约束   其他表达式按正则处理
+var builtinConstraints = map[string]*paramConstraint{
+	"int": {
+		expr:  "int",
+		match: isInt,
+		convert: func(key, value string) (any, error) {
+			return parseParamInt(key, value)
+		},
+	},
+	"uuid": {
+		expr:  "uuid",
+		match: isUUID,
+		convert: func(key, value string) (any, error) {
+			return parseParamUUID(key, value)
+		},
+	},
+}
+
+//解析参数段   :id<int> 返回 id 和 int约束   :id 返回 id 和 nil
+func parseParamSegment(path, seg string) (string, *paramConstraint) {
+	key := seg[1:]
+	i := strings.IndexByte(key, '<')
+	if i < 0 {
+		return key, nil
+	}
+	if !strings.HasSuffix(key, ">") || i == len(key)-2 {
+		panic(fmt.Sprintf("路由 %s 中的参数约束 %s 格式错误", path, seg))
+	}
+	expr := key[i+1 : len(key)-1]
+	key = key[:i]
+	if c, ok := builtinConstraints[expr]; ok {
+		return key, c
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("路由 %s 中的参数约束 %s 不是合法的正则: %v", path, seg, err))
+	}
+	return key, &paramConstraint{expr: expr, match: re.MatchString}
+}
+
+//参数名  :id<int> 返回 id
+func paramName(seg string) string {
+	key := seg[1:]
+	if i := strings.IndexByte(key, '<'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+func (c *paramConstraint) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.expr
+}
+
+//与转化参数时使用相同的解析  超出int范围的数字不满足约束
+func isInt(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -0,0 +1,71 @@
+package frame
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParamConstraint(t *testing.T) {
+	engine := New()
+	g := engine.Group("order")
+	g.Get("/getss/:id<int>", func(ctx *Context) {
+		id, _ := ctx.ParamValue("id")
+		ctx.String(http.StatusOK, "int %T %v", id, id)
+	})
+	g.Get("/getss/:uuid<uuid>", func(ctx *Context) {
+		id, _ := ctx.ParamValue("uuid")
+		ctx.String(http.StatusOK, "uuid %v", id)
+	})
+	g.Get("/getss/:slug<[a-z-]+>", func(ctx *Context) {
+		ctx.String(http.StatusOK, "slug %s", ctx.Param("slug"))
+	})
+	g.Get("/only/:id<int>", func(ctx *Context) {})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/order/getss/12", http.StatusOK, "int int 12"},
+		{"/order/getss/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", http.StatusOK, "uuid 6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"/order/getss/hello-world", http.StatusOK, "slug hello-world"},
+		{"/order/getss/ABC", http.StatusNotFound, ""},
+		{"/order/only/abc", http.StatusNotFound, ""},
+		//超出int范围时不满足int约束，继续匹配其他路由
+		{"/order/getss/99999999999999999999", http.StatusNotFound, ""},
+		{"/order/only/99999999999999999999", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, http.MethodGet, tt.path)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestParamConstraintConflict(t *testing.T) {
+	for _, routes := range [][]string{
+		{"/user/:id<int>", "/user/:uid<int>"},
+		{"/user/:id", "/user/:name"},
+		{"/user/:id<[0-9>", "/user/x"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v: expected panic", routes)
+				}
+			}()
+			root := &treeNode{name: "/"}
+			for _, route := range routes {
+				root.Put(route)
+			}
+		}()
+	}
+	root := &treeNode{name: "/"}
+	root.Put("/user/:id<int>")
+	root.Put("/user/:name")
+	var params Params
+	if n := root.Get("/user/jack", &params); n == nil || n.routerName != "/user/:name" || params.ByName("name") != "jack" {
+		t.Errorf("/user/jack matched %v %v", n, params)
+	}
+}
@@ -2,27 +2,37 @@ package frame
 
 import (
 	"errors"
+	"fmt"
 	"github.com/NBjjp/JpWebFrame/binding"
 	jplog "github.com/NBjjp/JpWebFrame/log"
 	"github.com/NBjjp/JpWebFrame/render"
 	"html/template"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 const defaultMultipartMemory = 32 << 20 //32M
 
+//Abort后index设置为该值  大于任何处理器链的长度
+const abortIndex = math.MaxInt32 >> 1
+
 //封装信息
 type Context struct {
-	W      http.ResponseWriter
-	R      *http.Request
-	engine *Engine
+	//写入响应  默认与Writer相同，中间件可以替换
+	W http.ResponseWriter
+	//记录状态码和响应大小   ctx.Writer.Written()  ctx.Writer.Size()
+	Writer    ResponseWriter
+	writermem responseWriter
+	R         *http.Request
+	engine    *Engine
 	//用于存储参数
 	queryCache url.Values
 	//
@@ -39,12 +49,86 @@ type Context struct {
 	mu   sync.RWMutex
 	//安全性操作
 	sameSite http.SameSite
+	//路由参数   /user/:id
+	params Params
+	//匹配到的路由节点
+	node *treeNode
+	//域名参数   :tenant.example.com
+	hostParams Params
+	//处理器链  中间件在前，路由处理器在最后
+	handlers []HandlerFunc
+	//当前执行到的处理器
+	index int
+}
+
+//从sync.Pool中取出后重置  不能保留上一个请求的任何数据
+//params和hostParams保留容量，避免每个请求重新分配
+func (ctx *Context) reset(w http.ResponseWriter, r *http.Request) {
+	ctx.writermem.reset(w)
+	ctx.Writer = &ctx.writermem
+	ctx.W = ctx.Writer
+	ctx.R = r
+	ctx.queryCache = nil
+	ctx.formCache = nil
+	ctx.StatusCode = 0
+	ctx.DisallowUnknownFields = false
+	ctx.IsValidate = false
+	ctx.Logger = ctx.engine.Logger
+	ctx.mu.Lock()
+	ctx.Keys = nil
+	ctx.mu.Unlock()
+	ctx.sameSite = 0
+	ctx.params = ctx.params[:0]
+	ctx.node = nil
+	ctx.hostParams = ctx.hostParams[:0]
+	ctx.handlers = nil
+	ctx.index = 0
+}
+
+//执行处理器链
+func (ctx *Context) handle(handlers []HandlerFunc) {
+	ctx.handlers = handlers
+	ctx.index = -1
+	ctx.Next()
+}
+
+//在中间件中调用  执行后续的处理器，全部执行完后返回
+func (ctx *Context) Next() {
+	ctx.index++
+	for ctx.index < len(ctx.handlers) {
+		ctx.handlers[ctx.index](ctx)
+		ctx.index++
+	}
+}
+
+//终止后续处理器的执行  当前处理器会继续执行完
+func (ctx *Context) Abort() {
+	ctx.index = abortIndex
+}
+
+//是否已经终止
+func (ctx *Context) IsAborted() bool {
+	return ctx.index >= abortIndex
+}
+
+//写入状态码并终止
+func (ctx *Context) AbortWithStatus(code int) {
+	ctx.W.WriteHeader(code)
+	ctx.StatusCode = code
+	ctx.Abort()
+}
+
+//返回JSON格式的信息并终止
+func (ctx *Context) AbortWithStatusJSON(code int, data any) error {
+	ctx.Abort()
+	return ctx.JSON(code, data)
 }
 
 func (ctx *Context) SetSameSite(s http.SameSite) {
 	ctx.sameSite = s
 }
-func (ctx *Context) Set(key string, value string) {
+//在Keys中保存数据  用于中间件和处理器之间传递数据，请求结束后清空
+func (ctx *Context) Set(key string, value any) {
 	ctx.mu.Lock()
 	if ctx.Keys == nil {
 		ctx.Keys = make(map[string]any)
@@ -62,6 +146,130 @@ func (ctx *Context) Get(key string) (value any, exists bool) {
 	return
 }
 
+//获取Keys中的数据  不存在时panic
+func (ctx *Context) MustGet(key string) any {
+	if value, exists := ctx.Get(key); exists {
+		return value
+	}
+	panic(fmt.Sprintf("key %s 不存在", key))
+}
+
+//获取Keys中的string  不存在或类型不是string时返回""
+func (ctx *Context) GetString(key string) string {
+	s, _ := Value[string](ctx, key)
+	return s
+}
+
+//获取Keys中的int  不存在或类型不是int时返回0
+func (ctx *Context) GetInt(key string) int {
+	i, _ := Value[int](ctx, key)
+	return i
+}
+
+//获取Keys中的time.Time  不存在或类型不是time.Time时返回零值
+func (ctx *Context) GetTime(key string) time.Time {
+	t, _ := Value[time.Time](ctx, key)
+	return t
+}
+
+//按类型获取Keys中的数据  不存在或类型不匹配时返回零值和false
+//  user, ok := frame.Value[*User](ctx, "user")
+func Value[T any](ctx *Context, key string) (T, bool) {
+	value, exists := ctx.Get(key)
+	if !exists {
+		var zero T
+		return zero, false
+	}
+	t, ok := value.(T)
+	return t, ok
+}
+
+//获取路由参数   /user/get/:id 访问/user/get/1   ctx.Param("id")返回1
+func (ctx *Context) Param(key string) string {
+	return ctx.params.ByName(key)
+}
+
+//获取路由参数  不存在时返回false
+func (ctx *Context) GetParam(key string) (string, bool) {
+	return ctx.params.Get(key)
+}
+
+//获取全部路由参数
+func (ctx *Context) Params() Params {
+	return ctx.params
+}
+
+//获取带约束的路由参数转化后的值   :id<int> 返回int  :id<uuid> 返回小写的uuid  其余返回字符串
+func (ctx *Context) ParamValue(key string) (any, bool) {
+	for i, p := range ctx.params {
+		if p.Key != key {
+			continue
+		}
+		if ctx.node != nil && i < len(ctx.node.constraints) {
+			if c := ctx.node.constraints[i]; c != nil && c.convert != nil {
+				value, err := c.convert(key, p.Value)
+				return value, err == nil
+			}
+		}
+		return p.Value, true
+	}
+	return nil, false
+}
+
+//获取域名参数   engine.Host(":tenant.example.com") 访问a.example.com  ctx.HostParam("tenant")返回a
+func (ctx *Context) HostParam(key string) string {
+	return ctx.hostParams.ByName(key)
+}
+
+//获取全部域名参数
+func (ctx *Context) HostParams() Params {
+	return ctx.hostParams
+}
+
+//获取 ** 匹配到的剩余路径   /static/** 访问/static/css/a.css 返回css/a.css
+func (ctx *Context) CatchAll() string {
+	return ctx.params.ByName(catchAllKey)
+}
+
+//将路由参数转化为int  转化失败返回400
+func (ctx *Context) ParamInt(key string) (int, error) {
+	i, err := ctx.ShouldParamInt(key)
+	if err != nil {
+		ctx.Fail(http.StatusBadRequest, err.Error())
+	}
+	return i, err
+}
+
+func (ctx *Context) ShouldParamInt(key string) (int, error) {
+	return parseParamInt(key, ctx.Param(key))
+}
+
+//将路由参数转化为int64  转化失败返回400
+func (ctx *Context) ParamInt64(key string) (int64, error) {
+	i, err := ctx.ShouldParamInt64(key)
+	if err != nil {
+		ctx.Fail(http.StatusBadRequest, err.Error())
+	}
+	return i, err
+}
+
+func (ctx *Context) ShouldParamInt64(key string) (int64, error) {
+	return parseParamInt64(key, ctx.Param(key))
+}
+
+//校验路由参数是否为uuid  不是返回400
+func (ctx *Context) ParamUUID(key string) (string, error) {
+	id, err := ctx.ShouldParamUUID(key)
+	if err != nil {
+		ctx.Fail(http.StatusBadRequest, err.Error())
+	}
+	return id, err
+}
+
+func (ctx *Context) ShouldParamUUID(key string) (string, error) {
+	return parseParamUUID(key, ctx.Param(key))
+}
+
 //用于获取参数      ?id=1&name=zhangsan
 func (ctx *Context) GetQuery(key string) string {
 	ctx.initQueryCache()
@@ -350,13 +558,16 @@ func (ctx *Context) FileFromFS(filepath string, fs http.FileSystem) {
 //	_, err1 := ctx.W.Write(StringtiBytes(format))
 //	return err1
 //}
-//多次调用WriteHeader  会产生 http: superfluous response.WriteHeader call  警告  TODO
+//先设置Content-Type和状态码再写入响应体  响应头在第一次写入响应体时发送
+//重定向的状态码和Location由http.Redirect写入
 func (ctx *Context) Render(status int, r render.Render) error {
-	ctx.W.WriteHeader(status)
-	err := r.Render(ctx.W)
 	ctx.StatusCode = status
-
-	return err
+	if _, ok := r.(*render.Redirect); ok {
+		return r.Render(ctx.W)
+	}
+	r.WriteContentType(ctx.W)
+	ctx.W.WriteHeader(status)
+	return r.Render(ctx.W)
 }
 
 //string支持   重构版本
@@ -397,7 +608,7 @@ func (ctx *Context) Template(name string, data any) error {
 	})
 }
 
-//重定向     重构版本     TODO 重复写writeheader   存在问题  需要修改
+//重定向     重构版本
 func (ctx *Context) Redirect(status int, url string) error {
 	return ctx.Render(status, &render.Redirect{
 		Code:     status,
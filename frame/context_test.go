@@ -0,0 +1,192 @@
+package frame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	Name string
+}
+
+func TestContextKeys(t *testing.T) {
+	ctx := &Context{}
+	now := time.Now()
+	user := &testUser{Name: "jp"}
+	ctx.Set("name", "jp")
+	ctx.Set("age", 18)
+	ctx.Set("login", now)
+	ctx.Set("user", user)
+
+	if v := ctx.GetString("name"); v != "jp" {
+		t.Errorf("GetString = %q", v)
+	}
+	if v := ctx.GetInt("age"); v != 18 {
+		t.Errorf("GetInt = %d", v)
+	}
+	if v := ctx.GetTime("login"); !v.Equal(now) {
+		t.Errorf("GetTime = %v", v)
+	}
+	if v := ctx.GetString("age"); v != "" {
+		t.Errorf("GetString(age) = %q, want empty for wrong type", v)
+	}
+	if v := ctx.GetInt("missing"); v != 0 {
+		t.Errorf("GetInt(missing) = %d", v)
+	}
+	if v, ok := Value[*testUser](ctx, "user"); !ok || v != user {
+		t.Errorf("Value[*testUser] = %v, %v", v, ok)
+	}
+	if _, ok := Value[string](ctx, "user"); ok {
+		t.Error("Value[string] on *testUser returned ok")
+	}
+	if v := ctx.MustGet("age"); v != 18 {
+		t.Errorf("MustGet = %v", v)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet(missing) did not panic")
+		}
+	}()
+	ctx.MustGet("missing")
+}
+
+func TestContextKeysResetBetweenRequests(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	g.Get("/set", func(ctx *Context) {
+		ctx.Set("user", "jp")
+	})
+	g.Get("/get", func(ctx *Context) {
+		if _, ok := ctx.Get("user"); ok {
+			ctx.String(http.StatusOK, "leaked")
+			return
+		}
+		ctx.String(http.StatusOK, "clean")
+	})
+	for i := 0; i < 10; i++ {
+		performRequest(engine, http.MethodGet, "/set")
+		if w := performRequest(engine, http.MethodGet, "/get"); w.Body.String() != "clean" {
+			t.Fatalf("Keys leaked from previous request")
+		}
+	}
+}
+
+func TestContextReset(t *testing.T) {
+	engine := New()
+	ctx := engine.allocateContext().(*Context)
+	r := httptest.NewRequest(http.MethodPost, "/a?x=1", strings.NewReader("y=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx.reset(httptest.NewRecorder(), r)
+	ctx.GetQuery("x")
+	ctx.GetPostForm("y")
+	ctx.StatusCode = http.StatusTeapot
+	ctx.DisallowUnknownFields = true
+	ctx.IsValidate = true
+	ctx.Set("user", "jp")
+	ctx.SetSameSite(http.SameSiteStrictMode)
+	ctx.params = append(ctx.params, Param{Key: "id", Value: "1"})
+	ctx.hostParams = append(ctx.hostParams, Param{Key: "tenant", Value: "a"})
+	ctx.node = &treeNode{}
+	ctx.handle([]HandlerFunc{func(ctx *Context) {}})
+
+	w := httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/b", nil)
+	ctx.reset(w, r)
+	//除下列字段外，重置后所有字段都应为零值  新增字段时需要在reset中处理
+	keep := map[string]bool{"W": true, "Writer": true, "writermem": true, "R": true, "engine": true, "mu": true}
+	v := reflect.ValueOf(ctx).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if keep[name] {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Slice {
+			if f.Len() != 0 {
+				t.Errorf("%s not reset: len = %d", name, f.Len())
+			}
+			continue
+		}
+		if !f.IsZero() {
+			t.Errorf("%s not reset", name)
+		}
+	}
+	if ctx.writermem.ResponseWriter != w || ctx.Writer.Written() || ctx.R != r {
+		t.Error("W and R not replaced")
+	}
+}
+
+//同一个Context被复用时不能看到上一个请求的数据
+func TestContextNoStateLeak(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	g.Get("/login", func(ctx *Context) {
+		ctx.GetQuery("user")
+		ctx.Set("user", ctx.GetQuery("user"))
+		ctx.SetSameSite(http.SameSiteStrictMode)
+		ctx.DisallowUnknownFields = true
+		ctx.String(http.StatusCreated, "ok")
+	})
+	g.Get("/whoami", func(ctx *Context) {
+		switch {
+		case ctx.GetString("user") != "":
+			ctx.String(http.StatusOK, "leaked Keys")
+		case ctx.GetQuery("user") != "":
+			ctx.String(http.StatusOK, "leaked queryCache")
+		case ctx.sameSite != 0 || ctx.DisallowUnknownFields:
+			ctx.String(http.StatusOK, "leaked options")
+		case ctx.StatusCode != 0:
+			ctx.String(http.StatusOK, "leaked StatusCode")
+		default:
+			ctx.String(http.StatusOK, "clean")
+		}
+	})
+	for i := 0; i < 20; i++ {
+		performRequest(engine, http.MethodGet, "/login?user=admin")
+		if w := performRequest(engine, http.MethodGet, "/whoami"); w.Body.String() != "clean" {
+			t.Fatalf("request %d: %s", i, w.Body.String())
+		}
+	}
+}
+
+//并发请求各自看到自己的参数和数据   配合 go test -race 运行
+func TestContextConcurrentRequests(t *testing.T) {
+	engine := New()
+	engine.UseHandler(func(ctx *Context) {
+		if _, ok := ctx.Get("id"); ok {
+			ctx.AbortWithStatus(http.StatusConflict)
+			return
+		}
+		ctx.Set("id", ctx.GetQuery("id"))
+		ctx.Next()
+	})
+	engine.Group("user").Get("/:id", func(ctx *Context) {
+		if ctx.Param("id") != ctx.GetString("id") {
+			ctx.String(http.StatusBadRequest, "param %s key %s", ctx.Param("id"), ctx.GetString("id"))
+			return
+		}
+		ctx.String(http.StatusOK, ctx.Param("id"))
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				id := strconv.Itoa(i*100 + j)
+				w := performRequest(engine, http.MethodGet, "/user/"+id+"?id="+id)
+				if w.Code != http.StatusOK || w.Body.String() != id {
+					t.Errorf("/user/%s = %d %q", id, w.Code, w.Body.String())
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
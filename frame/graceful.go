@@ -0,0 +1,107 @@
+package frame
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+//子进程通过这个环境变量得知继承了listener  listener的文件描述符为3
+const gracefulEnv = "JPFRAME_GRACEFUL"
+
+//平滑重启模式启动服务   仅支持Linux等类Unix系统
+//收到SIGHUP时启动新的进程(同样的程序和参数)，并把正在监听的socket传给新进程
+//新进程启动完成后向旧进程发送SIGTERM，旧进程不再接受新连接，处理完已有请求后退出
+//新进程启动失败时旧进程继续提供服务
+//  kill -HUP <pid>
+func (e *Engine) RunGraceful(addr string) error {
+	ln, inherited, err := gracefulListener(addr)
+	if err != nil {
+		return err
+	}
+	if inherited {
+		//启动完成后通知父进程退出
+		parent := os.Getppid()
+		e.ready = func() {
+			if p, err := os.FindProcess(parent); err == nil {
+				_ = p.Signal(syscall.SIGTERM)
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := forkChild(ln); err != nil && e.Logger != nil {
+					e.Logger.Error(fmt.Sprintf("graceful restart failed: %v", err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	srv := e.newServer(addr)
+	return e.serve(ctx, srv, ln, srv.Serve)
+}
+
+//由父进程传入listener时使用继承的listener，否则监听addr
+func gracefulListener(addr string) (net.Listener, bool, error) {
+	if os.Getenv(gracefulEnv) == "" {
+		ln, err := net.Listen("tcp", addr)
+		return ln, false, err
+	}
+	//避免再由这个进程启动的其他程序误用
+	_ = os.Unsetenv(gracefulEnv)
+	lns, err := fileListeners(systemdFdStart, 1, []string{"graceful"})
+	if err != nil {
+		return nil, false, err
+	}
+	return lns[0], true, nil
+}
+
+//以相同的程序和参数启动子进程  listener作为文件描述符3传入
+func forkChild(ln net.Listener) error {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return fmt.Errorf("listener %T does not support file descriptors", ln)
+	}
+	f, err := filer.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	env := make([]string, 0, len(os.Environ())+1)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, gracefulEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Env = append(env, gracefulEnv+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	//回收子进程  旧进程通常先退出，子进程由系统接管
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
@@ -0,0 +1,156 @@
+//go:build linux
+
+package frame
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const gracefulHelperEnv = "JPFRAME_GRACEFUL_HELPER_ADDR"
+
+//作为子进程运行的服务  由TestEngineGracefulRestart启动
+func TestGracefulHelper(t *testing.T) {
+	addr := os.Getenv(gracefulHelperEnv)
+	if addr == "" {
+		t.Skip("helper process")
+	}
+	engine := New()
+	g := engine.Group("")
+	g.Get("/pid", func(ctx *Context) {
+		ctx.String(http.StatusOK, "%d", os.Getpid())
+	})
+	g.Get("/slow", func(ctx *Context) {
+		time.Sleep(300 * time.Millisecond)
+		ctx.String(http.StatusOK, "%d", os.Getpid())
+	})
+	engine.OnStart(func() error {
+		_, err := os.Stdout.WriteString("ready " + strconv.Itoa(os.Getpid()) + "\n")
+		return err
+	})
+	if err := engine.RunGraceful(addr); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestEngineGracefulRestart(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts processes")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGracefulHelper$")
+	cmd.Env = append(os.Environ(), gracefulHelperEnv+"="+addr)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	ready := make(chan int, 2)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "ready ") {
+				n, _ := strconv.Atoi(strings.TrimPrefix(line, "ready "))
+				ready <- n
+			}
+		}
+	}()
+	waitReady := func() int {
+		select {
+		case pid := <-ready:
+			return pid
+		case <-time.After(10 * time.Second):
+			t.Fatal("server not ready")
+			return 0
+		}
+	}
+	parent := waitReady()
+	var child int
+	defer func() {
+		_ = cmd.Process.Kill()
+		if child != 0 {
+			_ = syscall.Kill(child, syscall.SIGKILL)
+		}
+	}()
+
+	get := func(path string) (string, error) {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+	if body, err := get("/pid"); err != nil || body != strconv.Itoa(parent) {
+		t.Fatalf("/pid = %q, %v, want %d", body, err, parent)
+	}
+
+	//重启过程中的请求由旧进程处理完成
+	type result struct {
+		body string
+		err  error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		body, err := get("/slow")
+		slow <- result{body, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := cmd.Process.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	child = waitReady()
+	if child == parent {
+		t.Fatalf("child pid = parent pid %d", parent)
+	}
+	if res := <-slow; res.err != nil || res.body != strconv.Itoa(parent) {
+		t.Errorf("in-flight /slow = %q, %v, want %d", res.body, res.err, parent)
+	}
+
+	//旧进程处理完请求后退出
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("parent exit = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("parent did not exit")
+	}
+
+	//新进程在同一个端口继续提供服务
+	for i := 0; i < 20; i++ {
+		body, err := get("/pid")
+		if err != nil || body != strconv.Itoa(child) {
+			t.Fatalf("/pid after restart = %q, %v, want %d", body, err, child)
+		}
+	}
+	if err := syscall.Kill(child, syscall.SIGTERM); err != nil {
+		t.Error(err)
+	}
+	child = 0
+}
@@ -0,0 +1,137 @@
+package frame
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//按域名划分的路由树
+type hostRouter struct {
+	//域名规则  admin.example.com  :tenant.example.com
+	pattern string
+	//按 . 切分后的各段
+	labels []string
+	//包含参数时匹配优先级低于固定域名
+	hasParam bool
+	tree     *treeNode
+}
+
+//创建只匹配指定域名的路由组   以 : 开头的一段为域名参数
+//engine.Host("admin.example.com")   engine.Host(":tenant.example.com")
+//固定域名优先于带参数的域名匹配，都不匹配时使用不区分域名的路由
+//匹配时不比较端口  engine.Host("localhost:8080") 与 engine.Host("localhost") 相同
+func (e *Engine) Host(pattern string) *routerGroup {
+	pattern = hostPatternWithoutPort(strings.ToLower(pattern))
+	var host *hostRouter
+	for _, h := range e.hosts {
+		if h.pattern == pattern {
+			host = h
+			break
+		}
+	}
+	if host == nil {
+		host = newHostRouter(pattern)
+		e.addHost(host)
+	}
+	group := &routerGroup{
+		name:     pattern,
+		basePath: "/",
+		router:   &e.router,
+		host:     host,
+	}
+	e.routergroups = append(e.routergroups, group)
+	return group
+}
+
+func newHostRouter(pattern string) *hostRouter {
+	if pattern == "" {
+		panic("域名不能为空")
+	}
+	h := &hostRouter{
+		pattern: pattern,
+		labels:  strings.Split(pattern, "."),
+		tree:    &treeNode{name: "/"},
+	}
+	for _, label := range h.labels {
+		if label == "" || label == ":" {
+			panic(fmt.Sprintf("域名 %s 格式错误", pattern))
+		}
+		if label[0] == ':' {
+			h.hasParam = true
+		}
+	}
+	return h
+}
+
+//固定域名放在带参数的域名之前
+func (r *router) addHost(host *hostRouter) {
+	i := len(r.hosts)
+	if !host.hasParam {
+		for i > 0 && r.hosts[i-1].hasParam {
+			i--
+		}
+	}
+	r.hosts = append(r.hosts, nil)
+	copy(r.hosts[i+1:], r.hosts[i:])
+	r.hosts[i] = host
+	n := 0
+	for _, label := range host.labels {
+		if label[0] == ':' {
+			n++
+		}
+	}
+	if n > r.maxHostParams {
+		r.maxHostParams = n
+	}
+}
+
+//匹配域名  匹配到的参数追加到params中，不匹配时params保持不变
+func (h *hostRouter) match(host string, params *Params) bool {
+	k := len(*params)
+	for i, label := range h.labels {
+		end := strings.IndexByte(host, '.')
+		if end < 0 {
+			end = len(host)
+		}
+		//最后一段必须正好用完host
+		if end == 0 || (i == len(h.labels)-1) != (end == len(host)) {
+			*params = (*params)[:k]
+			return false
+		}
+		value := host[:end]
+		if label[0] == ':' {
+			*params = append(*params, Param{Key: label[1:], Value: value})
+		} else if !strings.EqualFold(label, value) {
+			*params = (*params)[:k]
+			return false
+		}
+		if end < len(host) {
+			host = host[end+1:]
+		}
+	}
+	return true
+}
+
+//去掉域名规则中的端口   :tenant.example.com 开头的 : 是参数，不是端口
+func hostPatternWithoutPort(pattern string) string {
+	i := strings.LastIndexByte(pattern, ':')
+	if i <= 0 || i == len(pattern)-1 || pattern[i-1] == '.' {
+		return pattern
+	}
+	for _, c := range pattern[i+1:] {
+		if c < '0' || c > '9' {
+			return pattern
+		}
+	}
+	return pattern[:i]
+}
+
+//请求中的域名  去掉端口
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
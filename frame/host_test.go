@@ -0,0 +1,81 @@
+package frame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEngineHost(t *testing.T) {
+	engine := New()
+	engine.Host("admin.example.com").Get("/index", func(ctx *Context) {
+		ctx.String(http.StatusOK, "admin")
+	})
+	engine.Host(":tenant.example.com").Group("api").Get("/index", func(ctx *Context) {
+		ctx.String(http.StatusOK, "tenant %s", ctx.HostParam("tenant"))
+	})
+	engine.Host("api.example.com:8443").Get("/index", func(ctx *Context) {
+		ctx.String(http.StatusOK, "api")
+	})
+	engine.Group("").Get("/index", func(ctx *Context) {
+		ctx.String(http.StatusOK, "default")
+	})
+
+	tests := []struct {
+		host string
+		path string
+		code int
+		body string
+	}{
+		{"admin.example.com", "/index", http.StatusOK, "admin"},
+		{"ADMIN.example.com:8080", "/index", http.StatusOK, "admin"},
+		{"shop.example.com", "/api/index", http.StatusOK, "tenant shop"},
+		//带参数的域名没有该路由时使用默认路由
+		{"shop.example.com", "/index", http.StatusOK, "default"},
+		{"a.b.example.com", "/api/index", http.StatusNotFound, ""},
+		{"example.com", "/index", http.StatusOK, "default"},
+		//域名规则中的端口不参与匹配
+		{"api.example.com:8443", "/index", http.StatusOK, "api"},
+		{"api.example.com", "/index", http.StatusOK, "api"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		r.Host = tt.host
+		engine.ServeHTTP(w, r)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s%s: got %d %q, want %d %q", tt.host, tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestEngineHostRedirectIgnoreCase(t *testing.T) {
+	engine := New()
+	engine.RedirectFixedPath = true
+	engine.RedirectFixedPathIgnoreCase = true
+	engine.Host("admin.example.com").Get("/Dash", func(ctx *Context) {})
+	engine.Host(":tenant.example.com").Get("/Shop", func(ctx *Context) {})
+	engine.Group("").Get("/Index", func(ctx *Context) {})
+
+	tests := []struct {
+		host     string
+		path     string
+		code     int
+		location string
+	}{
+		{"admin.example.com", "/dash", http.StatusMovedPermanently, "/Dash"},
+		{"shop.example.com", "/shop", http.StatusMovedPermanently, "/Shop"},
+		{"admin.example.com", "/index", http.StatusMovedPermanently, "/Index"},
+		//其他域名的路由不参与查找
+		{"example.com", "/dash", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		r.Host = tt.host
+		engine.ServeHTTP(w, r)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s%s: code = %d, Location = %q, want %d %q", tt.host, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package jppool
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/NBjjp/JpWebFrame/config"
@@ -128,6 +129,23 @@ func (p *Pool) Submit(task func()) error {
 	return nil
 }
 
+//提交与请求关联的任务  ctx已经取消时不再提交，任务开始执行前ctx被取消则跳过
+//在处理器中传入ctx.R.Context()，不要传入*frame.Context：Context会被放回池中复用，异步使用会产生数据竞争
+//请求的context在处理器返回(响应结束)后即被取消，此时还没有开始执行的任务都会被跳过
+//  p.SubmitContext(ctx.R.Context(), task)
+//任务需要在响应结束后继续执行时使用Submit
+func (p *Pool) SubmitContext(ctx context.Context, task func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.Submit(func() {
+		if ctx.Err() != nil {
+			return
+		}
+		task()
+	})
+}
+
 //获取pool里面的worker
 func (p *Pool) GetWorker() *Worker {
 	//获取pool里面的worker
@@ -1,9 +1,11 @@
 package jppool
 
 import (
+	"context"
 	"math"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -75,3 +77,36 @@ func TestHasPool(t *testing.T) {
 	t.Logf("running worker:%d", pool.Running())
 	t.Logf("free worker:%d ", pool.Free())
 }
+
+func TestPoolSubmitContext(t *testing.T) {
+	p, _ := NewPool(1)
+	defer p.Release()
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	//占用唯一的worker
+	_ = p.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+	var ran int32
+	done := make(chan error, 1)
+	go func() {
+		done <- p.SubmitContext(ctx, func() {
+			atomic.StoreInt32(&ran, 1)
+		})
+	}()
+	cancel()
+	close(release)
+	if err := <-done; err != nil && err != context.Canceled {
+		t.Fatalf("SubmitContext = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt32(&ran) != 0 {
+		t.Error("task ran after context canceled")
+	}
+	if err := p.SubmitContext(ctx, func() {}); err != context.Canceled {
+		t.Errorf("SubmitContext with canceled ctx = %v", err)
+	}
+}
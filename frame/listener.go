@@ -0,0 +1,61 @@
+package frame
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//systemd传入的第一个文件描述符
+const systemdFdStart = 3
+
+//获取systemd socket activation传入的listener
+//systemd通过环境变量LISTEN_PID LISTEN_FDS LISTEN_FDNAMES传递，文件描述符从3开始
+//不是由systemd启动时返回nil，返回后清除这些环境变量，避免子进程重复使用
+//  lns, err := frame.SystemdListeners()
+//  engine.RunListener(lns[0])
+func SystemdListeners() ([]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	var names []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	return fileListeners(systemdFdStart, n, names)
+}
+
+//将从start开始的n个文件描述符转化为listener
+func fileListeners(start, n int, names []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("fd%d", start+i)
+		if i < len(names) {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(start+i), name)
+		ln, err := net.FileListener(f)
+		//FileListener复制了文件描述符
+		_ = f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("listener %s: %w", name, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package frame
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestFileListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	//模拟systemd  将listener的文件描述符传给fileListeners
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := syscall.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lns, err := fileListeners(fd, 1, []string{"http"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lns[0].Close()
+	if lns[0].Addr().String() != ln.Addr().String() {
+		t.Errorf("addr = %s, want %s", lns[0].Addr(), ln.Addr())
+	}
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestSystemdListenersOtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	lns, err := SystemdListeners()
+	if err != nil || lns != nil {
+		t.Errorf("SystemdListeners = %v, %v, want nil", lns, err)
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("LISTEN_FDS not cleared")
+	}
+}
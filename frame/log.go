@@ -129,7 +129,7 @@ func LoggerWithConfig(conf LoggerConfig, next HandlerFunc) HandlerFunc {
 		ip, _, _ := net.SplitHostPort(strings.TrimSpace(ctx.R.RemoteAddr))
 		clientIP := net.ParseIP(ip)
 		method := ctx.R.Method
-		statusCode := ctx.StatusCode
+		statusCode := ctx.Writer.Status()
 		if raw != "" {
 			path = path + "?" + raw
 		}
@@ -0,0 +1,38 @@
+package frame
+
+//处理器链中的中间件   name用于Routes中显示
+type middleware struct {
+	name    string
+	handler HandlerFunc
+}
+
+//将MiddlewareFunc转化为处理器链中的HandlerFunc
+//MiddlewareFunc中调用next即ctx.Next()，没有调用next时终止后续处理器，与ctx.Abort()相同
+func MiddlewareHandler(m MiddlewareFunc) HandlerFunc {
+	h := m(func(ctx *Context) {
+		ctx.Next()
+	})
+	return func(ctx *Context) {
+		index := ctx.index
+		h(ctx)
+		if ctx.index == index {
+			ctx.Abort()
+		}
+	}
+}
+
+func wrapMiddlewares(middlewareFuncs []MiddlewareFunc) []middleware {
+	middlewares := make([]middleware, 0, len(middlewareFuncs))
+	for _, m := range middlewareFuncs {
+		middlewares = append(middlewares, middleware{name: nameOfFunction(m), handler: MiddlewareHandler(m)})
+	}
+	return middlewares
+}
+
+func handlerMiddlewares(handlers []HandlerFunc) []middleware {
+	middlewares := make([]middleware, 0, len(handlers))
+	for _, h := range handlers {
+		middlewares = append(middlewares, middleware{name: nameOfFunction(h), handler: h})
+	}
+	return middlewares
+}
@@ -0,0 +1,90 @@
+package frame
+
+import (
+	"errors"
+	jplog "github.com/NBjjp/JpWebFrame/log"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestContextNextAbort(t *testing.T) {
+	engine := New()
+	var trace []string
+	engine.UseHandler(func(ctx *Context) {
+		trace = append(trace, "engine-before")
+		ctx.Next()
+		trace = append(trace, "engine-after")
+	})
+	g := engine.Group("user")
+	g.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			trace = append(trace, "func")
+			next(ctx)
+		}
+	})
+	g.UseHandler(func(ctx *Context) {
+		if ctx.GetQuery("deny") != "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, map[string]string{"error": "denied"})
+			return
+		}
+		trace = append(trace, "auth")
+	})
+	g.Get("/info", func(ctx *Context) {
+		trace = append(trace, "handler")
+	})
+
+	trace = nil
+	performRequest(engine, http.MethodGet, "/user/info")
+	want := []string{"engine-before", "func", "auth", "handler", "engine-after"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+
+	trace = nil
+	w := performRequest(engine, http.MethodGet, "/user/info?deny=1")
+	want = []string{"engine-before", "func", "engine-after"}
+	if !reflect.DeepEqual(trace, want) || w.Code != http.StatusForbidden {
+		t.Errorf("aborted: code = %d, trace = %v, want %v", w.Code, trace, want)
+	}
+}
+
+func TestMiddlewareFuncWithoutNextAborts(t *testing.T) {
+	engine := New()
+	auth := &Accounts{Users: map[string]string{"jjp": "123456"}}
+	handled := false
+	aborted := false
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			aborted = ctx.IsAborted()
+		}
+	})
+	engine.Group("user").Get("/info", func(ctx *Context) {
+		handled = true
+	}, auth.BasicAuth)
+
+	w := performRequest(engine, http.MethodGet, "/user/info")
+	if w.Code != http.StatusUnauthorized || handled || !aborted {
+		t.Errorf("code = %d, handled = %v, aborted = %v", w.Code, handled, aborted)
+	}
+}
+
+func TestRecoveryAbortsChain(t *testing.T) {
+	engine := New()
+	engine.Logger = jplog.Default()
+	engine.Logger.Outs = nil
+	handled := false
+	g := engine.Group("user")
+	g.Use(Recovery)
+	g.UseHandler(func(ctx *Context) {
+		panic(errors.New("boom"))
+	})
+	g.Get("/panic", func(ctx *Context) {
+		handled = true
+	})
+	w := performRequest(engine, http.MethodGet, "/user/panic")
+	if w.Code != http.StatusInternalServerError || handled {
+		t.Errorf("code = %d, handled = %v", w.Code, handled)
+	}
+}
@@ -1,6 +1,7 @@
 package frame
 
 import (
+	"context"
 	"fmt"
 	"github.com/NBjjp/JpWebFrame/config"
 	jplog "github.com/NBjjp/JpWebFrame/log"
@@ -8,7 +9,12 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const ANY = "ANY"
@@ -22,128 +28,268 @@ type MiddlewareFunc func(handlerFunc HandlerFunc) HandlerFunc
 type routerGroup struct {
 	//组名
 	name string
-	//第一个string为路由 第二个string为请求方式
-	handleFuncMap map[string]map[string]HandlerFunc
-	//为每个函数添加对应的中间件
-	middlewareFuncMap map[string]map[string][]MiddlewareFunc
+	//路由前缀  /user
+	basePath string
+	//所有组共用一棵路由树
+	router *router
+	//按域名路由时所属的域名  为nil时匹配所有域名
+	host *hostRouter
+	//通用中间件  按添加顺序执行
+	middlewares []middleware
+}
 
-	//第一个string对应请求方式  第二个string对应路由
-	//handlerMethodMap map[string][]string
-	treeNode *treeNode
-	//通用中间件
-	middlewares []MiddlewareFunc
+//注册的路由信息   Get Post等方法返回，可以通过Name为路由命名
+type Route struct {
+	method string
+	//完整路由  /user/get/:id
+	path    string
+	handler HandlerFunc
+	//路由中间件
+	middlewares []middleware
+	group       *routerGroup
+	//路由名称  用于反向生成URL
+	routeName string
+	//组合好的处理器链  全局中间件、组中间件、路由中间件、处理器
+	handlers []HandlerFunc
+	//超时时间  为0时不限制
+	timeout time.Duration
 }
 
 //向结构体中添加中间件
 func (r *routerGroup) Use(middlewareFunc ...MiddlewareFunc) {
-	r.middlewares = append(r.middlewares, middlewareFunc...)
+	r.middlewares = append(r.middlewares, wrapMiddlewares(middlewareFunc)...)
+	r.router.engine.changed()
 }
 
-//在处理器执行前后添加中间件
-func (r *routerGroup) methodHandle(name string, method string, h HandlerFunc, ctx *Context) {
-	//通用中间件
-	if r.middlewares != nil {
-		for _, middlewareFunc := range r.middlewares {
-			h = middlewareFunc(h)
-		}
-	}
-	//路由中间件
-	if r.middlewareFuncMap[name][method] != nil {
-		for _, middlewareFunc := range r.middlewareFuncMap[name][method] {
-			h = middlewareFunc(h)
-		}
-	}
-	h(ctx)
+//添加HandlerFunc形式的中间件  通过ctx.Next()执行后续处理器，ctx.Abort()终止
+func (r *routerGroup) UseHandler(handlers ...HandlerFunc) {
+	r.middlewares = append(r.middlewares, handlerMiddlewares(handlers)...)
+	r.router.engine.changed()
 }
 
-//func (r *routergroup) Add(name string, handleFunc HandleFunc) {
-//	r.handleFuncMap[name] = handleFunc
-//}
-
-func (r *routerGroup) handle(name string, method string, handleFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	_, ok := r.handleFuncMap[name]
-	if !ok {
-		r.handleFuncMap[name] = make(map[string]HandlerFunc)
-		r.middlewareFuncMap[name] = make(map[string][]MiddlewareFunc)
+//创建子路由组   前缀为父组前缀加name，继承父组此时已有的中间件
+//子组Use的中间件不会影响父组和其他子组
+func (r *routerGroup) Group(name string) *routerGroup {
+	group := &routerGroup{
+		name:        name,
+		basePath:    joinPaths(r.basePath, name),
+		router:      r.router,
+		host:        r.host,
+		middlewares: make([]middleware, len(r.middlewares)),
 	}
-	_, ok = r.handleFuncMap[name][method]
-	if ok {
-		panic("存在重复路由")
-	}
-	//不存在则增加路由
-	r.handleFuncMap[name][method] = handleFunc
-	r.middlewareFuncMap[name][method] = append(r.middlewareFuncMap[name][method], middlewareFunc...)
-	//添加到前缀树中
-	r.treeNode.Put(name)
+	copy(group.middlewares, r.middlewares)
+	r.router.routergroups = append(r.router.routergroups, group)
+	return group
+}
+
+func (r *routerGroup) handle(name string, method string, handleFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.router.addRoute(&Route{
+		method:      method,
+		path:        joinPaths(r.basePath, name),
+		handler:     handleFunc,
+		middlewares: wrapMiddlewares(middlewareFunc),
+		group:       r,
+	})
 }
 
 //处理任何访问方式  get post。。
-func (r *routerGroup) Any(name string, handleFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, ANY, handleFunc, middlewareFunc...)
+func (r *routerGroup) Any(name string, handleFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, ANY, handleFunc, middlewareFunc...)
 }
 
 //处理Get请求方式
-func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Get(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodGet, handlerFunc, middlewareFunc...)
 }
 
 //处理POST请求方式
-func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Post(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPost, handlerFunc, middlewareFunc...)
 }
 
-func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Delete(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodDelete, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Put(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPut, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Patch(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodPatch, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Options(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodOptions, handlerFunc, middlewareFunc...)
 }
-func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) {
-	r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
+func (r *routerGroup) Head(name string, handlerFunc HandlerFunc, middlewareFunc ...MiddlewareFunc) *Route {
+	return r.handle(name, http.MethodHead, handlerFunc, middlewareFunc...)
 }
 
 type router struct {
 	routergroups []*routerGroup
 	engine       *Engine
+	//所有路由组的路由放在同一棵前缀树中  节点上按请求方式存放处理器
+	tree *treeNode
+	//路由中参数最多的个数  用于提前分配Context中Params的容量
+	maxParams int
+	//命名路由  key为路由名称
+	namedRoutes map[string]*Route
+	//按注册顺序存放的全部路由
+	routes []*Route
+	//按域名划分的路由树   优先于tree匹配
+	hosts []*hostRouter
+	//域名中参数最多的个数
+	maxHostParams int
 }
 
 //初始化路由组
 func (r *router) Group(name string) *routerGroup {
 	routergroup := &routerGroup{
-		name:              name,
-		handleFuncMap:     make(map[string]map[string]HandlerFunc),
-		middlewareFuncMap: make(map[string]map[string][]MiddlewareFunc),
-		//handlerMethodMap: make(map[string][]string),
-		treeNode: &treeNode{name: "/", children: make([]*treeNode, 0)},
+		name:     name,
+		basePath: joinPaths("/", name),
+		router:   r,
 	}
-	routergroup.Use(r.engine.Middles...)
 	r.routergroups = append(r.routergroups, routergroup)
 	return routergroup
 }
 
+//添加到前缀树中
+func (r *router) addRoute(rt *Route) *Route {
+	tree := r.tree
+	if rt.group.host != nil {
+		tree = rt.group.host.tree
+	}
+	node := tree.Put(rt.path)
+	if node.routes == nil {
+		node.routes = make(map[string]*Route)
+	}
+	if _, ok := node.routes[rt.method]; ok {
+		panic(fmt.Sprintf("存在重复路由 %s %s", rt.method, rt.path))
+	}
+	node.routes[rt.method] = rt
+	r.routes = append(r.routes, rt)
+	r.engine.changed()
+	node.allow = allowHeader(node.routes)
+	if n := countParams(rt.path); n > r.maxParams {
+		r.maxParams = n
+	}
+	return rt
+}
+
+//节点上注册的请求方式  用于405和OPTIONS响应中的Allow头
+//注册了GET时自动支持HEAD，OPTIONS总是支持
+func allowHeader(routes map[string]*Route) string {
+	methods := []string{http.MethodOptions}
+	for method := range routes {
+		if method != ANY && method != http.MethodOptions {
+			methods = append(methods, method)
+		}
+	}
+	if _, ok := routes[http.MethodGet]; ok {
+		if _, ok := routes[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+//查找路由  匹配到的参数存放在ctx.params中
+//先查找与请求域名匹配的路由树，都没有匹配时查找不区分域名的路由树
+func (r *router) getRoute(path string, ctx *Context) *treeNode {
+	if cap(ctx.params) < r.maxParams {
+		ctx.params = make(Params, 0, r.maxParams)
+	}
+	if cap(ctx.hostParams) < r.maxHostParams {
+		ctx.hostParams = make(Params, 0, r.maxHostParams)
+	}
+	ctx.params = ctx.params[:0]
+	ctx.hostParams = ctx.hostParams[:0]
+	if len(r.hosts) > 0 {
+		host := requestHost(ctx.R)
+		for _, h := range r.hosts {
+			if !h.match(host, &ctx.hostParams) {
+				continue
+			}
+			if node := h.tree.Get(path, &ctx.params); node != nil {
+				return node
+			}
+			ctx.params = ctx.params[:0]
+			ctx.hostParams = ctx.hostParams[:0]
+		}
+	}
+	return r.tree.Get(path, &ctx.params)
+}
+
+//不区分大小写查找路由  返回注册时的路径   与getRoute相同先查找与请求域名匹配的路由树
+func (r *router) findCaseInsensitive(path string, ctx *Context) (string, bool) {
+	if len(r.hosts) > 0 {
+		host := requestHost(ctx.R)
+		for _, h := range r.hosts {
+			matched := h.match(host, &ctx.hostParams)
+			ctx.hostParams = ctx.hostParams[:0]
+			if !matched {
+				continue
+			}
+			if fixed, ok := h.tree.findCaseInsensitive(path, nil); ok {
+				return string(fixed), true
+			}
+		}
+	}
+	fixed, ok := r.tree.findCaseInsensitive(path, nil)
+	return string(fixed), ok
+}
+
 type Engine struct {
 	router
 	funcMap    template.FuncMap
 	HTMLRender render.HTMLRender
 	pool       sync.Pool
 	Logger     *jplog.Logger
-	Middles    []MiddlewareFunc
+	//通过Use添加的中间件
+	Middles []MiddlewareFunc
+	//全局中间件  包括Use和UseHandler添加的，按添加顺序执行
+	middlewares []middleware
+	//路由或中间件有变化  需要重新组合处理器链
+	dirty   int32
+	buildMu sync.Mutex
+	//组合好的404 405处理器链
+	noRouteHandlers  []HandlerFunc
+	noMethodHandlers []HandlerFunc
+	//404 405处理器
+	noRoute  HandlerFunc
+	noMethod HandlerFunc
+	//路由不存在时，如果去掉或加上末尾的 / 能匹配，则重定向   /user/get/ -> /user/get
+	RedirectTrailingSlash bool
+	//路由不存在时，清理路径(合并多余的 /，处理 . 和 ..)后能匹配则重定向   /user//get -> /user/get
+	RedirectFixedPath bool
+	//RedirectFixedPath时忽略大小写   /USER/get -> /user/get
+	RedirectFixedPathIgnoreCase bool
+	//启动时打印路由表
+	PrintRoutes bool
+	//http.Server的超时和请求头限制  Default()从配置文件[server]读取
+	ServerOptions ServerOptions
+	//优雅关闭时等待请求处理完成的最长时间  默认10秒
+	ShutdownTimeout time.Duration
+	startHooks      []StartHook
+	shutdownHooks   []ShutdownHook
+	serverMu        sync.Mutex
+	running         *runningServer
+	//开始提供服务后执行  平滑重启时用于通知父进程退出
+	ready func()
 }
 
 //sync.Pool用于存储那些被分配了但是没有被使用，但是未来可能被使用的值，这样可以不用再次分配内存，提高效率。
 //sync.Pool大小是可伸缩的，高负载是会动态扩容，存放在池中不活跃的对象会被自动清理。
 func New() *Engine {
 	engine := &Engine{
-		router:     router{},
-		funcMap:    nil,
-		HTMLRender: render.HTMLRender{},
+		router:        router{tree: &treeNode{name: "/"}},
+		HTMLRender:    render.HTMLRender{},
+		noRoute:       defaultNoRoute,
+		noMethod:      defaultNoMethod,
+		ServerOptions: DefaultServerOptions(),
 	}
+	engine.router.engine = engine
+	engine.SetFuncMap(nil)
+	engine.changed()
 	engine.pool.New = func() any {
 		return engine.allocateContext()
 	}
@@ -151,9 +297,9 @@ func New() *Engine {
 }
 func Default() *Engine {
 	engine := New()
-	engine.router.engine = engine
 	engine.Logger = jplog.Default()
 	engine.Use(Logging, Recovery)
+	engine.ServerOptions = ServerOptionsConf()
 	logpath, ok := config.Conf.Log["path"]
 	if ok {
 		engine.Logger.SetLogPath(logpath.(string))
@@ -165,8 +311,12 @@ func (e *Engine) allocateContext() any {
 	return &Context{engine: e}
 }
 
+//自定义模板函数  url函数会自动加入，用于在模板中按路由名称生成URL
 func (e *Engine) SetFuncMap(funcmap template.FuncMap) {
-	e.funcMap = funcmap
+	e.funcMap = template.FuncMap{"url": e.URL}
+	for name, fn := range funcmap {
+		e.funcMap[name] = fn
+	}
 }
 
 //将模板提前加载到内存中
@@ -193,52 +343,187 @@ func (e *Engine) SetHTMLTemplate(t *template.Template) {
 }
 func (e *Engine) httpRequestHandle(ctx *Context, w http.ResponseWriter, r *http.Request) {
 	method := r.Method
-	for _, group := range e.routergroups {
-		routerName := SubStringLast(r.URL.Path, "/"+group.name)
-		node := group.treeNode.Get(routerName)
-		if node != nil && node.isEnd {
-			//路由匹配
-			//ctx := &Context{
-			//	W:      w,
-			//	R:      r,
-			//	engine: e,
-			//}
-			handle, ok := group.handleFuncMap[node.routerName][ANY]
-			if ok {
-				group.methodHandle(node.routerName, ANY, handle, ctx)
-				return
-			}
+	node := e.getRoute(r.URL.Path, ctx)
+	ctx.node = node
+	if node != nil {
+		//路由匹配
+		rt, ok := node.routes[ANY]
+		if !ok {
 			//如果any不匹配则与对应的方法进行匹配
-			handle, ok = group.handleFuncMap[node.routerName][method]
-			if ok {
-				group.methodHandle(node.routerName, method, handle, ctx)
-				return
+			rt, ok = node.routes[method]
+		}
+		if !ok && method == http.MethodHead {
+			//没有注册HEAD时使用GET的处理器，丢弃响应体
+			if rt, ok = node.routes[http.MethodGet]; ok {
+				ctx.writermem.head = true
 			}
-			//路径一样，请求方式没有，返回405状态，
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			fmt.Fprintf(w, "%s %s 请求方式不允许\n", r.RequestURI, method)
+		}
+		if ok {
+			ctx.handle(rt.handlers)
 			return
 		}
+		w.Header().Set("Allow", node.allow)
+		//没有注册OPTIONS时自动返回支持的请求方式  同样执行全局中间件(CORS等)
+		if method == http.MethodOptions {
+			e.handleWithMiddles(func(ctx *Context) {
+				ctx.W.WriteHeader(http.StatusNoContent)
+				ctx.StatusCode = http.StatusNoContent
+			}, ctx)
+			return
+		}
+		//路径一样，请求方式没有，返回405状态，
+		ctx.handle(e.noMethodHandlers)
+		return
+	}
+	if method != http.MethodConnect && e.redirectPath(ctx) {
+		return
+	}
+	ctx.handle(e.noRouteHandlers)
+}
+
+//按RedirectTrailingSlash RedirectFixedPath查找规范的路由并重定向  找到返回true
+func (e *Engine) redirectPath(ctx *Context) bool {
+	if !e.RedirectTrailingSlash && !e.RedirectFixedPath {
+		return false
+	}
+	path := ctx.R.URL.Path
+	var candidates []string
+	if e.RedirectFixedPath {
+		candidates = append(candidates, cleanPath(path))
+	} else {
+		candidates = append(candidates, path)
+	}
+	if e.RedirectTrailingSlash {
+		p := candidates[0]
+		if strings.HasSuffix(p, "/") {
+			candidates = append(candidates, p[:len(p)-1])
+		} else {
+			candidates = append(candidates, p+"/")
+		}
+	}
+	for _, p := range candidates {
+		if p != path && e.getRoute(p, ctx) != nil {
+			e.redirect(ctx, p)
+			return true
+		}
+		if e.RedirectFixedPath && e.RedirectFixedPathIgnoreCase {
+			if fixed, ok := e.findCaseInsensitive(p, ctx); ok && fixed != path {
+				e.redirect(ctx, fixed)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//GET请求返回301  其他请求返回308，保证请求方式和请求体不变
+func (e *Engine) redirect(ctx *Context, path string) {
+	code := http.StatusMovedPermanently
+	if ctx.R.Method != http.MethodGet {
+		code = http.StatusPermanentRedirect
+	}
+	//path是解码后的路径  需要重新转义，否则 %3F 会变成查询参数的开始
+	location := (&url.URL{Path: path}).EscapedPath()
+	if ctx.R.URL.RawQuery != "" {
+		location += "?" + ctx.R.URL.RawQuery
+	}
+	ctx.params = ctx.params[:0]
+	e.handleWithMiddles(func(ctx *Context) {
+		ctx.W.Header().Set("Location", location)
+		ctx.W.WriteHeader(code)
+		ctx.StatusCode = code
+	}, ctx)
+}
+
+//不经过路由组的处理器(404 405 重定向)  同样执行全局中间件
+func (e *Engine) handleWithMiddles(h HandlerFunc, ctx *Context) {
+	ctx.handle(combineHandlers(e.middlewares, h))
+}
+
+//标记路由或中间件有变化
+func (e *Engine) changed() {
+	atomic.StoreInt32(&e.dirty, 1)
+}
+
+//按 全局中间件、组中间件、路由中间件、处理器 的顺序组合处理器链
+//在启动后第一次处理请求前执行，之后只有路由或中间件变化时才重新组合
+func (e *Engine) buildHandlers() {
+	if atomic.LoadInt32(&e.dirty) == 0 {
+		return
+	}
+	e.buildMu.Lock()
+	defer e.buildMu.Unlock()
+	if atomic.LoadInt32(&e.dirty) == 0 {
+		return
 	}
-	w.WriteHeader(http.StatusNotFound)
-	fmt.Fprintf(w, "%s  not found\n", r.RequestURI)
+	for _, rt := range e.routes {
+		rt.handlers = combineHandlers(e.routeMiddlewares(rt), rt.handler)
+	}
+	e.noRouteHandlers = combineHandlers(e.middlewares, e.noRoute)
+	e.noMethodHandlers = combineHandlers(e.middlewares, e.noMethod)
+	atomic.StoreInt32(&e.dirty, 0)
+}
+
+//路由按执行顺序的全部中间件  buildHandlers和Routes共用
+func (e *Engine) routeMiddlewares(rt *Route) []middleware {
+	middlewares := make([]middleware, 0, len(e.middlewares)+len(rt.group.middlewares)+len(rt.middlewares)+1)
+	middlewares = append(middlewares, e.middlewares...)
+	//超时包含组中间件和路由中间件的执行时间
+	if rt.timeout > 0 {
+		middlewares = append(middlewares, middleware{name: "Timeout", handler: Timeout(rt.timeout)})
+	}
+	middlewares = append(middlewares, rt.group.middlewares...)
+	return append(middlewares, rt.middlewares...)
+}
+
+func combineHandlers(middlewares []middleware, h HandlerFunc) []HandlerFunc {
+	handlers := make([]HandlerFunc, 0, len(middlewares)+1)
+	for _, m := range middlewares {
+		handlers = append(handlers, m.handler)
+	}
+	return append(handlers, h)
+}
+
+//设置路由不存在时的处理器  默认返回404
+func (e *Engine) NoRoute(handlerFunc HandlerFunc) {
+	e.noRoute = handlerFunc
+	e.changed()
+}
+
+//设置路由存在但请求方式不允许时的处理器  默认返回405  Allow头已经设置好
+func (e *Engine) NoMethod(handlerFunc HandlerFunc) {
+	e.noMethod = handlerFunc
+	e.changed()
+}
+
+func defaultNoRoute(ctx *Context) {
+	ctx.String(http.StatusNotFound, "%s  not found\n", ctx.R.RequestURI)
+}
+
+func defaultNoMethod(ctx *Context) {
+	ctx.String(http.StatusMethodNotAllowed, "%s %s method not allowed\n", ctx.R.RequestURI, ctx.R.Method)
 }
 
 //实现handler接口中serveHTTP方法
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := e.pool.Get().(*Context)
-	ctx.W = w
-	ctx.R = r
-	ctx.Logger = e.Logger
-	e.httpRequestHandle(ctx, w, r)
+	ctx.reset(w, r)
+	e.buildHandlers()
+	e.httpRequestHandle(ctx, ctx.W, r)
+	//处理器只调用了WriteHeader没有写入响应体
+	ctx.Writer.WriteHeaderNow()
 	e.pool.Put(ctx)
 }
+
+//启动https服务  收到SIGINT SIGTERM时优雅关闭
 func (e *Engine) RunTLS(addr, certFile, keyFile string) {
-	err := http.ListenAndServeTLS(addr, certFile, keyFile, e.Handler())
+	err := e.RunTLSContext(context.Background(), addr, certFile, keyFile)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+//启动服务  收到SIGINT SIGTERM时优雅关闭
 func (e *Engine) Run(addr string) {
 	//for _, group := range e.routergroups {
 	//	//group:user key:get value:func
@@ -246,8 +531,7 @@ func (e *Engine) Run(addr string) {
 	//		http.HandleFunc("/"+group.name+key, value)
 	//	}
 	//}
-	http.Handle("/", e)
-	err := http.ListenAndServe(addr, nil)
+	err := e.RunContext(context.Background(), addr)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -256,6 +540,14 @@ func (e *Engine) Run(addr string) {
 //引擎中间件   添加日志中间件
 func (e *Engine) Use(middles ...MiddlewareFunc) {
 	e.Middles = append(e.Middles, middles...)
+	e.middlewares = append(e.middlewares, wrapMiddlewares(middles)...)
+	e.changed()
+}
+
+//添加HandlerFunc形式的全局中间件  通过ctx.Next()执行后续处理器，ctx.Abort()终止
+func (e *Engine) UseHandler(handlers ...HandlerFunc) {
+	e.middlewares = append(e.middlewares, handlerMiddlewares(handlers)...)
+	e.changed()
 }
 
 func (e *Engine) Handler() http.Handler {
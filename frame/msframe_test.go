@@ -0,0 +1,257 @@
+package frame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func performRequest(e *Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestEngineGroupPrefix(t *testing.T) {
+	engine := New()
+	user := engine.Group("user")
+	user.Get("/get", func(ctx *Context) {
+		ctx.String(http.StatusOK, "user")
+	})
+	admin := engine.Group("user-admin")
+	admin.Get("/get", func(ctx *Context) {
+		ctx.String(http.StatusOK, "user-admin")
+	})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/user/get", http.StatusOK, "user"},
+		{"/user-admin/get", http.StatusOK, "user-admin"},
+		//组名只匹配路径开头
+		{"/api/user/get", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, http.MethodGet, tt.path)
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.path, w.Code, tt.code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, w.Body.String(), tt.body)
+		}
+	}
+	if w := performRequest(engine, http.MethodPost, "/user/get"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /user/get: code = %d, want 405", w.Code)
+	}
+}
+
+func TestEngineNestedGroup(t *testing.T) {
+	engine := New()
+	var trace []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx *Context) {
+				trace = append(trace, name)
+				next(ctx)
+			}
+		}
+	}
+	api := engine.Group("api")
+	api.Use(mark("api"))
+	v1 := api.Group("v1")
+	v1.Use(mark("v1"))
+	v2 := api.Group("/v2")
+	//创建子组之后父组添加的中间件不影响子组
+	api.Use(mark("api-late"))
+	v1.Get("/user", func(ctx *Context) {})
+	v2.Get("/user", func(ctx *Context) {})
+
+	trace = nil
+	if w := performRequest(engine, http.MethodGet, "/api/v1/user"); w.Code != http.StatusOK {
+		t.Fatalf("/api/v1/user: code = %d", w.Code)
+	}
+	if len(trace) != 2 || trace[0] != "api" || trace[1] != "v1" {
+		t.Errorf("/api/v1/user: middlewares = %v, want [api v1]", trace)
+	}
+	trace = nil
+	if w := performRequest(engine, http.MethodGet, "/api/v2/user"); w.Code != http.StatusOK {
+		t.Fatalf("/api/v2/user: code = %d", w.Code)
+	}
+	if len(trace) != 1 || trace[0] != "api" {
+		t.Errorf("/api/v2/user: middlewares = %v, want [api]", trace)
+	}
+}
+
+func TestEngineMiddlewareOrder(t *testing.T) {
+	engine := New()
+	var trace []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx *Context) {
+				trace = append(trace, name)
+				next(ctx)
+			}
+		}
+	}
+	engine.Use(mark("engine1"))
+	g := engine.Group("user")
+	g.Use(mark("group1"), mark("group2"))
+	g.Get("/info", func(ctx *Context) {
+		trace = append(trace, "handler")
+	}, mark("route"))
+	//创建路由组之后添加的全局中间件同样生效
+	engine.Use(mark("engine2"))
+
+	performRequest(engine, http.MethodGet, "/user/info")
+	want := []string{"engine1", "engine2", "group1", "group2", "route", "handler"}
+	if strings.Join(trace, " ") != strings.Join(want, " ") {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+
+	//启动后添加的中间件在下一个请求时生效
+	engine.Use(mark("engine3"))
+	trace = nil
+	performRequest(engine, http.MethodGet, "/nothing")
+	want = []string{"engine1", "engine2", "engine3"}
+	if strings.Join(trace, " ") != strings.Join(want, " ") {
+		t.Errorf("404 trace = %v, want %v", trace, want)
+	}
+}
+
+func TestEngineAllowOptionsHead(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/info", func(ctx *Context) {
+		ctx.String(http.StatusOK, "info")
+	})
+	g.Post("/info", func(ctx *Context) {})
+
+	w := performRequest(engine, http.MethodDelete, "/user/info")
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: code = %d, want 405", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, POST" {
+		t.Errorf("DELETE: Allow = %q", allow)
+	}
+
+	w = performRequest(engine, http.MethodOptions, "/user/info")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Errorf("OPTIONS: code = %d, Allow = %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w = performRequest(engine, http.MethodHead, "/user/info")
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("HEAD: code = %d, body = %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct == "" {
+		t.Error("HEAD: missing Content-Type from GET handler")
+	}
+
+	//通过ctx.Writer写入时同样丢弃响应体，Flusher可用
+	var flusher bool
+	g.Get("/raw", func(ctx *Context) {
+		_, _ = ctx.Writer.Write([]byte("body"))
+		_, flusher = ctx.W.(http.Flusher)
+	})
+	w = performRequest(engine, http.MethodHead, "/user/raw")
+	if w.Code != http.StatusOK || w.Body.Len() != 0 || !flusher {
+		t.Errorf("HEAD /raw: code = %d, body = %q, Flusher = %v", w.Code, w.Body.String(), flusher)
+	}
+	w = performRequest(engine, http.MethodGet, "/user/raw")
+	if w.Body.String() != "body" {
+		t.Errorf("GET /raw after HEAD: body = %q", w.Body.String())
+	}
+}
+
+func TestEngineOptionsMiddleware(t *testing.T) {
+	engine := New()
+	var logged []int
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			ctx.W.Header().Set("Access-Control-Allow-Origin", "*")
+			next(ctx)
+			logged = append(logged, ctx.StatusCode)
+		}
+	})
+	engine.Group("user").Post("/login", func(ctx *Context) {})
+
+	w := performRequest(engine, http.MethodOptions, "/user/login")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "OPTIONS, POST" {
+		t.Errorf("OPTIONS: code = %d, Allow = %q", w.Code, w.Header().Get("Allow"))
+	}
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("OPTIONS: Access-Control-Allow-Origin = %q, want *", origin)
+	}
+	if len(logged) != 1 || logged[0] != http.StatusNoContent {
+		t.Errorf("logged = %v, want [204]", logged)
+	}
+}
+
+func TestEngineNoRouteNoMethod(t *testing.T) {
+	engine := New()
+	var logged []int
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			logged = append(logged, ctx.StatusCode)
+		}
+	})
+	engine.NoRoute(func(ctx *Context) {
+		ctx.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	})
+	engine.NoMethod(func(ctx *Context) {
+		ctx.JSON(http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	})
+	engine.Group("user").Get("/info", func(ctx *Context) {})
+
+	w := performRequest(engine, http.MethodGet, "/missing")
+	if w.Code != http.StatusNotFound || w.Body.String() != `{"error":"not found"}` {
+		t.Errorf("404: code = %d, body = %q", w.Code, w.Body.String())
+	}
+	w = performRequest(engine, http.MethodPost, "/user/info")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") == "" {
+		t.Errorf("405: code = %d, Allow = %q", w.Code, w.Header().Get("Allow"))
+	}
+	if len(logged) != 2 || logged[0] != http.StatusNotFound || logged[1] != http.StatusMethodNotAllowed {
+		t.Errorf("middleware saw %v, want [404 405]", logged)
+	}
+}
+
+func TestEngineRedirectPath(t *testing.T) {
+	engine := New()
+	engine.RedirectTrailingSlash = true
+	engine.RedirectFixedPath = true
+	engine.RedirectFixedPathIgnoreCase = true
+	g := engine.Group("user")
+	g.Get("/hello/get", func(ctx *Context) {})
+	g.Get("/dir/", func(ctx *Context) {})
+	g.Get("/get/:id", func(ctx *Context) {})
+	g.Post("/hello/get", func(ctx *Context) {})
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		location string
+	}{
+		{http.MethodGet, "/user/hello/get/", http.StatusMovedPermanently, "/user/hello/get"},
+		{http.MethodGet, "/user/dir", http.StatusMovedPermanently, "/user/dir/"},
+		{http.MethodGet, "/user//hello/get?a=1", http.StatusMovedPermanently, "/user/hello/get?a=1"},
+		{http.MethodGet, "/user/x/../hello/get", http.StatusMovedPermanently, "/user/hello/get"},
+		{http.MethodGet, "/USER/Get/AbC", http.StatusMovedPermanently, "/user/get/AbC"},
+		//参数中的 ? 和空格重定向时保持转义
+		{http.MethodGet, "/user/get/a%3Fb/", http.StatusMovedPermanently, "/user/get/a%3Fb"},
+		{http.MethodGet, "/user/get/a%20b/?c=1", http.StatusMovedPermanently, "/user/get/a%20b?c=1"},
+		{http.MethodPost, "/user/hello/get/", http.StatusPermanentRedirect, "/user/hello/get"},
+		{http.MethodGet, "/user/none", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, tt.method, tt.path)
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: code = %d, Location = %q, want %d %q", tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
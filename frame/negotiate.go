@@ -0,0 +1,158 @@
+package frame
+
+import (
+	"errors"
+	"github.com/NBjjp/JpWebFrame/render"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIMEJSON  = "application/json"
+	MIMEXML   = "application/xml"
+	MIMEXML2  = "text/xml"
+	MIMEHTML  = "text/html"
+	MIMEPlain = "text/plain"
+)
+
+//没有客户端可以接受的格式
+var ErrNotAcceptable = errors.New("the accepted formats are not offered by the server")
+
+//按Accept请求头选择返回格式   没有设置数据的格式不参与选择
+//  ctx.Negotiate(http.StatusOK, frame.Negotiate{Data: user, HTMLName: "user.html"})
+type Negotiate struct {
+	//返回JSON时的数据  为nil时使用Data
+	JSON any
+	//返回XML时的数据  为nil时使用Data
+	XML any
+	//返回HTML时的数据  设置了HTMLName时作为模板数据，否则必须是string
+	//为nil时使用Data
+	HTML any
+	//模板名称  使用engine加载的模板
+	HTMLName string
+	//各个格式共用的数据
+	Data any
+}
+
+//可以返回的格式  按服务端的优先顺序
+func (n *Negotiate) offered() []string {
+	offered := make([]string, 0, 4)
+	if n.JSON != nil || n.Data != nil {
+		offered = append(offered, MIMEJSON)
+	}
+	if n.XML != nil || n.Data != nil {
+		offered = append(offered, MIMEXML, MIMEXML2)
+	}
+	if n.HTMLName != "" {
+		offered = append(offered, MIMEHTML)
+	} else if _, ok := n.HTML.(string); ok {
+		offered = append(offered, MIMEHTML)
+	}
+	return offered
+}
+
+func pick(data, def any) any {
+	if data != nil {
+		return data
+	}
+	return def
+}
+
+//按Accept请求头返回JSON XML或HTML  都不能接受时返回406和ErrNotAcceptable
+func (ctx *Context) Negotiate(status int, config Negotiate) error {
+	switch ctx.NegotiateFormat(config.offered()...) {
+	case MIMEJSON:
+		return ctx.JSON(status, pick(config.JSON, config.Data))
+	case MIMEXML, MIMEXML2:
+		return ctx.XML(status, pick(config.XML, config.Data))
+	case MIMEHTML:
+		if config.HTMLName != "" {
+			return ctx.Render(status, &render.HTML{
+				Data:       pick(config.HTML, config.Data),
+				Name:       config.HTMLName,
+				IsTemplate: true,
+				Template:   ctx.engine.HTMLRender.Template,
+			})
+		}
+		return ctx.HTML(status, config.HTML.(string))
+	}
+	ctx.Abort()
+	ctx.String(http.StatusNotAcceptable, ErrNotAcceptable.Error())
+	return ErrNotAcceptable
+}
+
+//从offered中选出客户端最能接受的格式  都不能接受时返回""
+//按Accept中的q值选择，q值相同时按offered的顺序；没有Accept请求头时返回offered[0]
+//  ctx.NegotiateFormat(frame.MIMEJSON, frame.MIMEHTML)
+func (ctx *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		return ""
+	}
+	accept := strings.Join(ctx.R.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return offered[0]
+	}
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, o := range offered {
+		if q := acceptQuality(ranges, o); q > bestQ {
+			best, bestQ = o, q
+		}
+	}
+	return best
+}
+
+//Accept中的一项   text/html;q=0.8
+type acceptRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	ranges := make([]acceptRange, 0, 4)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+		r := acceptRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			r.q = q
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+//客户端对某个格式的q值  使用最具体的匹配项：text/html 优先于 text/* 优先于 */*
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	typ, subtype, _ := strings.Cut(strings.ToLower(mediaType), "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
@@ -0,0 +1,81 @@
+package frame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	offered := []string{MIMEJSON, MIMEXML, MIMEHTML}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MIMEJSON},
+		{"*/*", MIMEJSON},
+		{"application/xml", MIMEXML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", MIMEHTML},
+		{"application/json;q=0.5, application/xml;q=0.8", MIMEXML},
+		{"text/*", MIMEHTML},
+		{"application/*;q=0.2, text/html;q=0.1", MIMEJSON},
+		{"*/*;q=0.5, application/json;q=0", MIMEXML},
+		{"APPLICATION/XML; charset=utf-8", MIMEXML},
+		{"image/png", ""},
+		{"application/json;q=0", ""},
+		{"text/html;q=bad", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		ctx := &Context{R: r}
+		if got := ctx.NegotiateFormat(offered...); got != tt.want {
+			t.Errorf("Accept %q: NegotiateFormat = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+type negotiateUser struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestContextNegotiate(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	g.Get("/user", func(ctx *Context) {
+		ctx.Negotiate(http.StatusOK, Negotiate{
+			Data: negotiateUser{Name: "jp"},
+			HTML: "<b>jp</b>",
+		})
+	})
+	g.Get("/json", func(ctx *Context) {
+		ctx.Negotiate(http.StatusCreated, Negotiate{JSON: negotiateUser{Name: "jp"}})
+	})
+
+	tests := []struct {
+		path, accept string
+		code         int
+		contentType  string
+		body         string
+	}{
+		{"/user", "application/json", http.StatusOK, "application/json; charset=utf-8", `{"name":"jp"}`},
+		{"/user", "text/xml", http.StatusOK, "application/xml; charset=utf-8", `<negotiateUser><name>jp</name></negotiateUser>`},
+		{"/user", "text/html", http.StatusOK, "text/html;charset=utf-8", "<b>jp</b>"},
+		{"/json", "", http.StatusCreated, "application/json; charset=utf-8", `{"name":"jp"}`},
+		{"/json", "text/html", http.StatusNotAcceptable, "text/plain; charset=utf-8", ErrNotAcceptable.Error()},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.contentType || w.Body.String() != tt.body {
+			t.Errorf("%s Accept %q: %d %q %q, want %d %q %q", tt.path, tt.accept,
+				w.Code, w.Header().Get("Content-Type"), w.Body.String(), tt.code, tt.contentType, tt.body)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -30,6 +31,8 @@ type JpSession struct {
 	updateParam strings.Builder
 	whereParam  strings.Builder
 	whereValues []any
+	//请求取消或超时时中断执行中的sql
+	ctx context.Context
 }
 
 func Open(driverName string, source string) *JpDb {
@@ -78,6 +81,22 @@ func (db *JpDb) NewSession(data any) *JpSession {
 	}
 	return J
 }
+//设置执行sql使用的context   在处理器中传入ctx.R.Context()，客户端断开连接时取消查询
+//  db.NewSession(&user).WithContext(ctx.R.Context()).SelectOne(&user)
+//请求的context在处理器返回(响应结束)后即被取消，session不能在响应结束后继续使用(如在新的goroutine中)
+//*frame.Context会被放回池中复用，不要保存在session中异步使用
+func (s *JpSession) WithContext(ctx context.Context) *JpSession {
+	s.ctx = ctx
+	return s
+}
+
+func (s *JpSession) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 func (s *JpSession) Table(name string) *JpSession {
 	s.tableName = name
 	return s
@@ -92,14 +111,14 @@ func (s *JpSession) Insert(data any) (int64, int64, error) {
 	var stmt *sql.Stmt
 	var err error
 	if s.beginTx {
-		stmt, err = s.tx.Prepare(query)
+		stmt, err = s.tx.PrepareContext(s.context(), query)
 	} else {
-		stmt, err = s.db.db.Prepare(query)
+		stmt, err = s.db.db.PrepareContext(s.context(), query)
 	}
 	if err != nil {
 		return -1, -1, err
 	}
-	r, err := stmt.Exec(s.values...)
+	r, err := stmt.ExecContext(s.context(), s.values...)
 	if err != nil {
 		return -1, -1, err
 	}
@@ -178,14 +197,14 @@ func (s *JpSession) InsertBatch(data []any) (int64, int64, error) {
 	var stmt *sql.Stmt
 	var err error
 	if s.beginTx {
-		stmt, err = s.tx.Prepare(sb.String())
+		stmt, err = s.tx.PrepareContext(s.context(), sb.String())
 	} else {
-		stmt, err = s.db.db.Prepare(sb.String())
+		stmt, err = s.db.db.PrepareContext(s.context(), sb.String())
 	}
 	if err != nil {
 		return -1, -1, err
 	}
-	r, err := stmt.Exec(s.values...)
+	r, err := stmt.ExecContext(s.context(), s.values...)
 	if err != nil {
 		return -1, -1, err
 	}
@@ -272,15 +291,15 @@ func (s *JpSession) UpDate(data ...any) (int64, int64, error) {
 		var stmt *sql.Stmt
 		var err error
 		if s.beginTx {
-			stmt, err = s.tx.Prepare(sb.String())
+			stmt, err = s.tx.PrepareContext(s.context(), sb.String())
 		} else {
-			stmt, err = s.db.db.Prepare(sb.String())
+			stmt, err = s.db.db.PrepareContext(s.context(), sb.String())
 		}
 		if err != nil {
 			return -1, -1, err
 		}
 		s.values = append(s.values, s.whereValues...)
-		r, err := stmt.Exec(s.values...)
+		r, err := stmt.ExecContext(s.context(), s.values...)
 		if err != nil {
 			return -1, -1, err
 		}
@@ -358,15 +377,15 @@ func (s *JpSession) UpDate(data ...any) (int64, int64, error) {
 	var stmt *sql.Stmt
 	var err error
 	if s.beginTx {
-		stmt, err = s.tx.Prepare(sb.String())
+		stmt, err = s.tx.PrepareContext(s.context(), sb.String())
 	} else {
-		stmt, err = s.db.db.Prepare(sb.String())
+		stmt, err = s.db.db.PrepareContext(s.context(), sb.String())
 	}
 	if err != nil {
 		return -1, -1, err
 	}
 	s.values = append(s.values, s.whereValues...)
-	r, err := stmt.Exec(s.values...)
+	r, err := stmt.ExecContext(s.context(), s.values...)
 	if err != nil {
 		return -1, -1, err
 	}
@@ -396,11 +415,11 @@ func (s *JpSession) SelectOne(data any, fields ...string) error {
 	sb.WriteString(sqlString)
 	sb.WriteString(s.whereParam.String())
 	s.db.logger.Info(sb.String())
-	stmt, err := s.db.db.Prepare(sb.String())
+	stmt, err := s.db.db.PrepareContext(s.context(), sb.String())
 	if err != nil {
 		return err
 	}
-	rows, err := stmt.Query(s.whereValues...)
+	rows, err := stmt.QueryContext(s.context(), s.whereValues...)
 	if err != nil {
 		return err
 	}
@@ -461,11 +480,11 @@ func (s *JpSession) Select(data any, fields ...string) ([]any, error) {
 	sb.WriteString(sqlString)
 	sb.WriteString(s.whereParam.String())
 	s.db.logger.Info(sb.String())
-	stmt, err := s.db.db.Prepare(sb.String())
+	stmt, err := s.db.db.PrepareContext(s.context(), sb.String())
 	if err != nil {
 		return nil, err
 	}
-	rows, err := stmt.Query(s.whereValues...)
+	rows, err := stmt.QueryContext(s.context(), s.whereValues...)
 	if err != nil {
 		return nil, err
 	}
@@ -531,14 +550,14 @@ func (s *JpSession) Delete() (int64, error) {
 	var stmt *sql.Stmt
 	var err error
 	if s.beginTx {
-		stmt, err = s.tx.Prepare(sqlString)
+		stmt, err = s.tx.PrepareContext(s.context(), sqlString)
 	} else {
-		stmt, err = s.db.db.Prepare(sqlString)
+		stmt, err = s.db.db.PrepareContext(s.context(), sqlString)
 	}
 	if err != nil {
 		return 0, nil
 	}
-	result, err := stmt.Exec(s.whereValues...)
+	result, err := stmt.ExecContext(s.context(), s.whereValues...)
 	if err != nil {
 		return 0, err
 	}
@@ -562,11 +581,11 @@ func (s *JpSession) Aggregate(funcName string, field string) (int64, error) {
 	sb.WriteString(sqlString)
 	sb.WriteString(s.whereParam.String())
 	s.db.logger.Info(sb.String())
-	stmt, err := s.db.db.Prepare(sb.String())
+	stmt, err := s.db.db.PrepareContext(s.context(), sb.String())
 	if err != nil {
 		return 0, err
 	}
-	row := stmt.QueryRow(s.whereValues...)
+	row := stmt.QueryRowContext(s.context(), s.whereValues...)
 	if row.Err() != nil {
 		return 0, err
 	}
@@ -583,14 +602,14 @@ func (s *JpSession) Exec(query string, values ...any) (int64, error) {
 	var stmt *sql.Stmt
 	var err error
 	if s.beginTx {
-		stmt, err = s.tx.Prepare(query)
+		stmt, err = s.tx.PrepareContext(s.context(), query)
 	} else {
-		stmt, err = s.db.db.Prepare(query)
+		stmt, err = s.db.db.PrepareContext(s.context(), query)
 	}
 	if err != nil {
 		return 0, err
 	}
-	r, err := stmt.Exec(values)
+	r, err := stmt.ExecContext(s.context(), values)
 	if err != nil {
 		return 0, err
 	}
@@ -601,11 +620,11 @@ func (s *JpSession) Exec(query string, values ...any) (int64, error) {
 }
 func (s *JpSession) QueryRow(sql string, data any, queryValues ...any) error {
 	t := reflect.TypeOf(data)
-	stmt, err := s.db.db.Prepare(sql)
+	stmt, err := s.db.db.PrepareContext(s.context(), sql)
 	if err != nil {
 		return err
 	}
-	rows, err := stmt.Query(queryValues...)
+	rows, err := stmt.QueryContext(s.context(), queryValues...)
 	if err != nil {
 		return err
 	}
@@ -734,7 +753,7 @@ func (s *JpSession) Order(field ...string) *JpSession {
 
 //事务支持
 func (s *JpSession) Begin() error {
-	tx, err := s.db.db.Begin()
+	tx, err := s.db.db.BeginTx(s.context(), nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,112 @@
+package frame
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//路由中 :name * ** 所匹配到的值的key
+const (
+	//  *  匹配一段路径
+	wildcardKey = "*"
+	//  ** 匹配剩余的全部路径
+	catchAllKey = "**"
+)
+
+//路由参数   /user/get/:id  访问/user/get/1  key为id value为1
+type Param struct {
+	Key   string
+	Value string
+}
+
+//路由参数列表  按路由中出现的顺序存放
+type Params []Param
+
+//获取路由参数  key不存在返回false
+func (ps Params) Get(key string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+//获取路由参数  key不存在返回空字符串
+func (ps Params) ByName(key string) string {
+	value, _ := ps.Get(key)
+	return value
+}
+
+//路由参数转化失败的错误    对应400状态码
+type ParamError struct {
+	Key   string
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("param [%s] value [%s] is not a valid %s: %v", e.Key, e.Value, e.Type, e.Err)
+	}
+	return fmt.Sprintf("param [%s] value [%s] is not a valid %s", e.Key, e.Value, e.Type)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+//返回对应的状态码
+func (e *ParamError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func parseParamInt(key, value string) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Key: key, Value: value, Type: "int", Err: err}
+	}
+	return i, nil
+}
+
+func parseParamInt64(key, value string) (int64, error) {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, &ParamError{Key: key, Value: value, Type: "int64", Err: err}
+	}
+	return i, nil
+}
+
+//校验uuid格式  xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx  返回小写形式
+func parseParamUUID(key, value string) (string, error) {
+	if !isUUID(value) {
+		return "", &ParamError{Key: key, Value: value, Type: "uuid"}
+	}
+	return strings.ToLower(value), nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,44 @@
+package frame
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextParam(t *testing.T) {
+	engine := New()
+	g := engine.Group("order")
+	g.Get("/get/:id", func(ctx *Context) {
+		id, err := ctx.ParamInt("id")
+		if err != nil {
+			return
+		}
+		ctx.String(http.StatusOK, "%d", id)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order/get/12", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "12" {
+		t.Errorf("got %d %q, want 200 \"12\"", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order/get/abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got %d, want 400", w.Code)
+	}
+}
+
+func TestParseParamUUID(t *testing.T) {
+	id, err := parseParamUUID("id", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	if err != nil || id != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+		t.Errorf("got %q %v", id, err)
+	}
+	_, err = parseParamUUID("id", "6ba7b810-9dad-11d1-80b4")
+	var paramErr *ParamError
+	if !errors.As(err, &paramErr) || paramErr.StatusCode() != http.StatusBadRequest {
+		t.Errorf("got %v, want ParamError", err)
+	}
+}
@@ -28,6 +28,8 @@ func Recovery(next HandlerFunc) HandlerFunc {
 	return func(ctx *Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				//panic之后的处理器不再执行
+				ctx.Abort()
 				err2 := err.(error)
 				if err2 != nil {
 					var jpError *jperror.JpError
@@ -0,0 +1,117 @@
+package frame
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+//还没有写入响应时size的值
+const noWritten = -1
+
+//记录状态码和响应大小的http.ResponseWriter
+//WriteHeader只记录状态码，第一次写入响应体(或请求结束)时才发送响应头
+//多次调用WriteHeader时以发送前最后一次为准，不会产生superfluous response.WriteHeader call
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	//状态码  没有调用WriteHeader时为200
+	Status() int
+	//已写入的响应体字节数  没有写入时为-1
+	Size() int
+	//响应头是否已经发送
+	Written() bool
+	//立即发送响应头
+	WriteHeaderNow()
+	//HTTP/2 server push  不支持时返回nil
+	Pusher() http.Pusher
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	//HEAD请求使用GET的处理器  只发送响应头，丢弃响应体
+	head bool
+}
+
+var _ ResponseWriter = (*responseWriter)(nil)
+
+func (w *responseWriter) reset(writer http.ResponseWriter) {
+	w.ResponseWriter = writer
+	w.status = http.StatusOK
+	w.size = noWritten
+	w.head = false
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	if w.head {
+		return len(data), nil
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.size != noWritten
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+//接管连接后不再由框架发送响应头
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the ResponseWriter does not support hijacking")
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	return h.Hijack()
+}
+
+func (w *responseWriter) Pusher() http.Pusher {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p
+	}
+	return nil
+}
+
+//用于http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,153 @@
+package frame
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+//记录WriteHeader的调用次数
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writeHeaders int
+}
+
+func (w *countingWriter) WriteHeader(code int) {
+	w.writeHeaders++
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func TestResponseWriterStatusAndSize(t *testing.T) {
+	engine := New()
+	var buf bytes.Buffer
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return LoggerWithConfig(LoggerConfig{
+			out: &buf,
+			Formatter: func(params *LogFormatterParams) string {
+				return strconv.Itoa(params.StatusCode)
+			},
+		}, next)
+	})
+	var written bool
+	var size int
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			written, size = ctx.Writer.Written(), ctx.Writer.Size()
+		}
+	})
+	g := engine.Group("")
+	g.Get("/raw", func(ctx *Context) {
+		//直接写入ctx.W  不经过Render
+		ctx.W.WriteHeader(http.StatusAccepted)
+		_, _ = ctx.W.Write([]byte("hello"))
+	})
+	g.Get("/empty", func(ctx *Context) {})
+
+	w := performRequest(engine, http.MethodGet, "/raw")
+	if w.Code != http.StatusAccepted || buf.String() != "202" {
+		t.Errorf("/raw: code = %d, logged %q", w.Code, buf.String())
+	}
+	if !written || size != 5 {
+		t.Errorf("/raw: Written = %v, Size = %d", written, size)
+	}
+
+	buf.Reset()
+	w = performRequest(engine, http.MethodGet, "/empty")
+	if w.Code != http.StatusOK || buf.String() != "200" {
+		t.Errorf("/empty: code = %d, logged %q", w.Code, buf.String())
+	}
+	if written || size != -1 {
+		t.Errorf("/empty: Written = %v, Size = %d", written, size)
+	}
+}
+
+func TestResponseWriterSingleWriteHeader(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	g.Get("/json", func(ctx *Context) {
+		ctx.JSON(http.StatusCreated, map[string]string{"a": "b"})
+		//已经写入响应体  不再修改状态码
+		ctx.W.WriteHeader(http.StatusInternalServerError)
+	})
+	g.Get("/abort", func(ctx *Context) {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	})
+	g.Get("/redirect", func(ctx *Context) {
+		ctx.Redirect(http.StatusFound, "/json")
+	})
+
+	w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/json", nil))
+	if w.writeHeaders != 1 || w.Code != http.StatusCreated {
+		t.Errorf("/json: WriteHeader called %d times, code = %d", w.writeHeaders, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("/json: Content-Type = %q", ct)
+	}
+
+	w = &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/abort", nil))
+	if w.writeHeaders != 1 || w.Code != http.StatusUnauthorized {
+		t.Errorf("/abort: WriteHeader called %d times, code = %d", w.writeHeaders, w.Code)
+	}
+
+	w = &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/redirect", nil))
+	if w.writeHeaders != 1 || w.Code != http.StatusFound || w.Header().Get("Location") != "/json" {
+		t.Errorf("/redirect: WriteHeader called %d times, code = %d, Location = %q", w.writeHeaders, w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestResponseWriterFlushHijack(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	g.Get("/flush", func(ctx *Context) {
+		ctx.W.Header().Set("X-Stream", "1")
+		ctx.Writer.Flush()
+		if !ctx.Writer.Written() {
+			t.Error("Written = false after Flush")
+		}
+		if ctx.Writer.Pusher() != nil {
+			t.Error("Pusher not nil for recorder")
+		}
+	})
+	g.Get("/hijack", func(ctx *Context) {
+		conn, rw, err := ctx.Writer.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+	})
+
+	w := performRequest(engine, http.MethodGet, "/flush")
+	if !w.Flushed || w.Header().Get("X-Stream") != "1" {
+		t.Errorf("/flush: Flushed = %v, header = %v", w.Flushed, w.Header())
+	}
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if string(body) != "hijacked" {
+		t.Errorf("/hijack: body = %q", body)
+	}
+
+	//recorder不支持Hijack
+	var ctx Context
+	ctx.writermem.reset(httptest.NewRecorder())
+	if _, _, err := ctx.writermem.Hijack(); err == nil {
+		t.Error("Hijack on recorder succeeded")
+	}
+}
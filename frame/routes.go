@@ -0,0 +1,72 @@
+package frame
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"text/tabwriter"
+)
+
+//路由信息  用于查看注册了哪些路由
+type RouteInfo struct {
+	Method string `json:"method"`
+	//按域名路由时的域名  为空时匹配所有域名
+	Host string `json:"host,omitempty"`
+	//完整路由  /user/get/:id
+	Path string `json:"path"`
+	//路由名称  未命名为空
+	Name string `json:"name,omitempty"`
+	//处理器函数名
+	Handler string `json:"handler"`
+	//按执行顺序排列的中间件函数名  包含全局、超时、路由组和路由中间件
+	Middlewares []string `json:"middlewares"`
+}
+
+//按注册顺序返回全部路由
+func (e *Engine) Routes() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(e.routes))
+	for _, rt := range e.routes {
+		//与buildHandlers使用相同的中间件  包括Timeout
+		routeMiddlewares := e.routeMiddlewares(rt)
+		middlewares := make([]string, 0, len(routeMiddlewares))
+		for _, m := range routeMiddlewares {
+			middlewares = append(middlewares, m.name)
+		}
+		host := ""
+		if rt.group.host != nil {
+			host = rt.group.host.pattern
+		}
+		infos = append(infos, RouteInfo{
+			Method:      rt.method,
+			Host:        host,
+			Path:        rt.path,
+			Name:        rt.routeName,
+			Handler:     nameOfFunction(rt.handler),
+			Middlewares: middlewares,
+		})
+	}
+	return infos
+}
+
+//以JSON格式返回路由表  用于调试
+//g.Get("/debug/routes", engine.RoutesHandler)
+func (e *Engine) RoutesHandler(ctx *Context) {
+	ctx.JSON(http.StatusOK, e.Routes())
+}
+
+//打印路由表
+func (e *Engine) printRoutes(out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tHOST\tPATH\tNAME\tHANDLER\tMIDDLEWARES")
+	for _, info := range e.Routes() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%v\n", info.Method, info.Host, info.Path, info.Name, info.Handler, info.Middlewares)
+	}
+	w.Flush()
+}
+
+//获取函数名   github.com/NBjjp/JpWebFrame.Logging
+func nameOfFunction(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
@@ -0,0 +1,46 @@
+package frame
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRouteHandler(ctx *Context) {}
+
+func TestEngineRoutes(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Use(Recovery)
+	g.Get("/get/:id", testRouteHandler, Logging).Name("user.get")
+	g.Post("/create", testRouteHandler)
+
+	g.Get("/report", testRouteHandler).Timeout(time.Second)
+
+	routes := engine.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("len(Routes()) = %d, want 3", len(routes))
+	}
+	r := routes[0]
+	if r.Method != http.MethodGet || r.Path != "/user/get/:id" || r.Name != "user.get" {
+		t.Errorf("route = %+v", r)
+	}
+	if !strings.HasSuffix(r.Handler, ".testRouteHandler") {
+		t.Errorf("handler = %s", r.Handler)
+	}
+	if len(r.Middlewares) != 2 || !strings.HasSuffix(r.Middlewares[0], ".Recovery") || !strings.HasSuffix(r.Middlewares[1], ".Logging") {
+		t.Errorf("middlewares = %v", r.Middlewares)
+	}
+	//超时中间件在路由组中间件之前执行
+	if m := routes[2].Middlewares; len(m) != 2 || m[0] != "Timeout" || !strings.HasSuffix(m[1], ".Recovery") {
+		t.Errorf("timeout route middlewares = %v", m)
+	}
+
+	var buf bytes.Buffer
+	engine.printRoutes(&buf)
+	if !strings.Contains(buf.String(), "/user/create") {
+		t.Errorf("printRoutes output missing route:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,291 @@
+package frame
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/NBjjp/JpWebFrame/config"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+//默认的关闭等待时间
+const defaultShutdownTimeout = 10 * time.Second
+
+//http.Server的超时和请求头限制   为0时表示不限制
+type ServerOptions struct {
+	//读取请求头的超时时间  防止slowloris攻击
+	ReadHeaderTimeout time.Duration
+	//读取整个请求(包括请求体)的超时时间
+	ReadTimeout time.Duration
+	//从读完请求头到写完响应的超时时间
+	WriteTimeout time.Duration
+	//keep-alive连接空闲的超时时间
+	IdleTimeout time.Duration
+	//请求头最大字节数
+	MaxHeaderBytes int
+	//不加密的连接上支持HTTP/2(h2c)  用于内部的gRPC等服务
+	H2C bool
+}
+
+//默认配置   不设置超时时慢速客户端可以一直占用连接
+func DefaultServerOptions() ServerOptions {
+	return ServerOptions{
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+	}
+}
+
+//通过配置文件配置  没有配置的项使用默认值
+//[server]
+//read_header_timeout="5s"
+//read_timeout="30s"
+//write_timeout="30s"
+//idle_timeout="2m"
+//max_header_bytes=1048576
+//h2c=true
+//时间可以是 "5s" 形式的字符串，也可以是整数秒
+func ServerOptionsConf() ServerOptions {
+	return serverOptionsFrom(config.Conf.Server)
+}
+
+func serverOptionsFrom(conf map[string]any) ServerOptions {
+	opts := DefaultServerOptions()
+	opts.ReadHeaderTimeout = confDuration(conf, "read_header_timeout", opts.ReadHeaderTimeout)
+	opts.ReadTimeout = confDuration(conf, "read_timeout", opts.ReadTimeout)
+	opts.WriteTimeout = confDuration(conf, "write_timeout", opts.WriteTimeout)
+	opts.IdleTimeout = confDuration(conf, "idle_timeout", opts.IdleTimeout)
+	if v, ok := conf["max_header_bytes"].(int64); ok {
+		opts.MaxHeaderBytes = int(v)
+	}
+	if v, ok := conf["h2c"].(bool); ok {
+		opts.H2C = v
+	}
+	return opts
+}
+
+func confDuration(conf map[string]any, key string, def time.Duration) time.Duration {
+	switch v := conf[key].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			panic(fmt.Sprintf("conf server.%s 格式错误: %v", key, err))
+		}
+		return d
+	case int64:
+		return time.Duration(v) * time.Second
+	}
+	return def
+}
+
+//按ServerOptions创建http.Server
+func (e *Engine) newServer(addr string) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           e,
+		ReadHeaderTimeout: e.ServerOptions.ReadHeaderTimeout,
+		ReadTimeout:       e.ServerOptions.ReadTimeout,
+		WriteTimeout:      e.ServerOptions.WriteTimeout,
+		IdleTimeout:       e.ServerOptions.IdleTimeout,
+		MaxHeaderBytes:    e.ServerOptions.MaxHeaderBytes,
+	}
+	if e.ServerOptions.H2C {
+		enableH2C(srv)
+	}
+	return srv
+}
+
+//启动时执行的函数  返回错误时不再启动
+type StartHook func() error
+
+//关闭时执行的函数  在所有连接处理完成(或超时)后执行
+//用于刷新日志、关闭数据库 orm.JpDb.Close()、释放协程池 jppool.Pool.Release()
+type ShutdownHook func(ctx context.Context) error
+
+//正在运行的服务
+type runningServer struct {
+	srv  *http.Server
+	once sync.Once
+	//Shutdown完成后关闭
+	done chan struct{}
+	err  error
+}
+
+//添加启动时执行的函数  按添加顺序执行
+func (e *Engine) OnStart(hooks ...StartHook) {
+	e.startHooks = append(e.startHooks, hooks...)
+}
+
+//添加关闭时执行的函数  按添加顺序执行，某个函数出错不影响后面的函数
+func (e *Engine) OnShutdown(hooks ...ShutdownHook) {
+	e.shutdownHooks = append(e.shutdownHooks, hooks...)
+}
+
+//启动服务  ctx结束或收到SIGINT SIGTERM时优雅关闭
+//关闭时不再接受新连接，等待已有请求处理完成，最多等待ShutdownTimeout
+//正常关闭时返回nil
+func (e *Engine) RunContext(ctx context.Context, addr string) error {
+	srv := e.newServer(addr)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.serve(ctx, srv, ln, srv.Serve)
+}
+
+//启动https服务  关闭方式与RunContext相同
+func (e *Engine) RunTLSContext(ctx context.Context, addr, certFile, keyFile string) error {
+	srv := e.newServer(addr)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.serve(ctx, srv, ln, func(ln net.Listener) error {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	})
+}
+
+//使用自定义的http.Server启动服务  不使用ServerOptions，Handler为nil时使用Engine
+//srv.TLSConfig中配置了证书时启动https服务
+func (e *Engine) RunServer(srv *http.Server) error {
+	if srv.Handler == nil {
+		srv.Handler = e
+	}
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+		if hasCertificate(srv.TLSConfig) {
+			addr = ":https"
+		}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if hasCertificate(srv.TLSConfig) {
+		return e.serve(context.Background(), srv, ln, func(ln net.Listener) error {
+			return srv.ServeTLS(ln, "", "")
+		})
+	}
+	return e.serve(context.Background(), srv, ln, srv.Serve)
+}
+
+func hasCertificate(c *tls.Config) bool {
+	return c != nil && (len(c.Certificates) > 0 || c.GetCertificate != nil)
+}
+
+//在已有的listener上启动服务  用于systemd socket activation等由外部创建listener的场景
+func (e *Engine) RunListener(ln net.Listener) error {
+	srv := e.newServer(ln.Addr().String())
+	return e.serve(context.Background(), srv, ln, srv.Serve)
+}
+
+//监听unix socket   已存在的socket文件会被删除，关闭时删除socket文件
+func (e *Engine) RunUnix(socketPath string) error {
+	if info, err := os.Stat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s already exists and is not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return err
+		}
+	}
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+	return e.RunListener(ln)
+}
+
+//关闭服务  等待已有请求处理完成，ctx结束时强制关闭剩余连接，然后执行OnShutdown添加的函数
+//服务没有运行时直接返回nil
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.serverMu.Lock()
+	rs := e.running
+	e.serverMu.Unlock()
+	if rs == nil {
+		return nil
+	}
+	rs.once.Do(func() {
+		err := rs.srv.Shutdown(ctx)
+		if err != nil {
+			//超时  强制关闭
+			_ = rs.srv.Close()
+		}
+		for _, hook := range e.shutdownHooks {
+			if hookErr := hook(ctx); hookErr != nil && err == nil {
+				err = hookErr
+			}
+		}
+		rs.err = err
+		close(rs.done)
+	})
+	<-rs.done
+	return rs.err
+}
+
+func (e *Engine) serve(ctx context.Context, srv *http.Server, ln net.Listener, serve func(net.Listener) error) error {
+	e.buildHandlers()
+	for _, hook := range e.startHooks {
+		if err := hook(); err != nil {
+			_ = ln.Close()
+			return err
+		}
+	}
+	rs := &runningServer{srv: srv, done: make(chan struct{})}
+	e.serverMu.Lock()
+	if e.running != nil {
+		e.serverMu.Unlock()
+		_ = ln.Close()
+		return errors.New("engine is already running")
+	}
+	e.running = rs
+	e.serverMu.Unlock()
+	defer func() {
+		e.serverMu.Lock()
+		e.running = nil
+		e.serverMu.Unlock()
+	}()
+	if e.PrintRoutes {
+		e.printRoutes(DefaultWriter)
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serve(ln)
+	}()
+	if e.ready != nil {
+		e.ready()
+	}
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		//由Shutdown关闭  等待关闭完成
+		<-rs.done
+		return rs.err
+	case <-ctx.Done():
+	case <-sig:
+	}
+	timeout := e.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return e.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,257 @@
+package frame
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//在随机端口启动服务  返回地址和serve的结果
+func startEngine(t *testing.T, e *Engine, ctx context.Context) (string, chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: e}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.serve(ctx, srv, ln, srv.Serve)
+	}()
+	return "http://" + ln.Addr().String(), errCh
+}
+
+func TestEngineShutdownDrains(t *testing.T) {
+	engine := New()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	engine.Group("").Get("/slow", func(ctx *Context) {
+		close(entered)
+		<-release
+		ctx.String(http.StatusOK, "done")
+	})
+	var trace []string
+	engine.OnStart(func() error {
+		trace = append(trace, "start")
+		return nil
+	})
+	engine.OnShutdown(func(ctx context.Context) error {
+		trace = append(trace, "shutdown1")
+		return nil
+	}, func(ctx context.Context) error {
+		trace = append(trace, "shutdown2")
+		return nil
+	})
+	addr, errCh := startEngine(t, engine, context.Background())
+
+	type result struct {
+		body string
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(addr + "/slow")
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		resCh <- result{body: string(body), err: err}
+	}()
+	<-entered
+
+	shutdownCh := make(chan error, 1)
+	go func() {
+		shutdownCh <- engine.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-shutdownCh:
+		t.Fatalf("Shutdown returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	if res := <-resCh; res.err != nil || res.body != "done" {
+		t.Errorf("in-flight request = %q, %v", res.body, res.err)
+	}
+	if err := <-shutdownCh; err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("serve = %v", err)
+	}
+	if len(trace) != 3 || trace[0] != "start" || trace[1] != "shutdown1" || trace[2] != "shutdown2" {
+		t.Errorf("hooks = %v", trace)
+	}
+}
+
+func TestEngineRunContextCancel(t *testing.T) {
+	engine := New()
+	engine.Group("").Get("/ping", func(ctx *Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+	closed := false
+	engine.OnShutdown(func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, errCh := startEngine(t, engine, ctx)
+	resp, err := http.Get(addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Errorf("serve = %v", err)
+	}
+	if !closed {
+		t.Error("OnShutdown hook not called")
+	}
+	if _, err := http.Get(addr + "/ping"); err == nil {
+		t.Error("server still accepts connections after shutdown")
+	}
+}
+
+func TestEngineShutdownTimeout(t *testing.T) {
+	engine := New()
+	engine.ShutdownTimeout = 20 * time.Millisecond
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	engine.Group("").Get("/slow", func(ctx *Context) {
+		close(entered)
+		<-release
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, errCh := startEngine(t, engine, ctx)
+	go func() {
+		resp, err := http.Get(addr + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("serve = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestEngineOnStartError(t *testing.T) {
+	engine := New()
+	want := errors.New("db not ready")
+	engine.OnStart(func() error {
+		return want
+	})
+	_, errCh := startEngine(t, engine, context.Background())
+	if err := <-errCh; err != want {
+		t.Errorf("serve = %v, want %v", err, want)
+	}
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown without running server = %v", err)
+	}
+}
+
+func TestServerOptionsFrom(t *testing.T) {
+	opts := serverOptionsFrom(map[string]any{
+		"read_header_timeout": "5s",
+		"idle_timeout":        int64(60),
+		"max_header_bytes":    int64(4096),
+	})
+	def := DefaultServerOptions()
+	if opts.ReadHeaderTimeout != 5*time.Second || opts.IdleTimeout != time.Minute || opts.MaxHeaderBytes != 4096 {
+		t.Errorf("options = %+v", opts)
+	}
+	if opts.ReadTimeout != def.ReadTimeout || opts.WriteTimeout != def.WriteTimeout {
+		t.Errorf("options without config = %+v, want defaults %+v", opts, def)
+	}
+
+	engine := New()
+	engine.ServerOptions = opts
+	srv := engine.newServer(":8080")
+	if srv.ReadHeaderTimeout != 5*time.Second || srv.MaxHeaderBytes != 4096 || srv.Handler != engine {
+		t.Errorf("server = %+v", srv)
+	}
+}
+
+func TestEngineRunListener(t *testing.T) {
+	engine := New()
+	engine.Group("").Get("/ping", func(ctx *Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- engine.RunListener(ln)
+	}()
+	resp, err := http.Get("http://" + ln.Addr().String() + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("body = %q", body)
+	}
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("RunListener = %v", err)
+	}
+}
+
+func TestEngineRunUnix(t *testing.T) {
+	engine := New()
+	engine.Group("").Get("/ping", func(ctx *Context) {
+		ctx.String(http.StatusOK, "pong")
+	})
+	socket := filepath.Join(t.TempDir(), "frame.sock")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- engine.RunUnix(socket)
+	}()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix/ping"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Errorf("body = %q", body)
+	}
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("RunUnix = %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("socket file not removed: %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package frame
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//静态文件配置
+type StaticConfig struct {
+	//目录中没有index.html时是否列出目录中的文件  默认返回404
+	ListDirectory bool
+	//文件不存在时返回的文件  单页应用设置为 /index.html，由前端处理路由
+	Fallback string
+}
+
+//将目录挂载到prefix下   g.Static("/assets", "./public")  访问/user/assets/css/a.css 返回./public/css/a.css
+func (r *routerGroup) Static(prefix, dir string, config ...StaticConfig) {
+	r.StaticFS(prefix, http.Dir(dir), config...)
+}
+
+//将文件系统挂载到prefix下
+//embed.FS 通过http.FS转化   g.StaticFS("/assets", http.FS(assets))
+func (r *routerGroup) StaticFS(prefix string, fs http.FileSystem, config ...StaticConfig) {
+	s := &staticServer{fs: fs}
+	if len(config) > 0 {
+		s.config = config[0]
+	}
+	r.Get(joinPaths(prefix, catchAllKey), s.serve)
+}
+
+//将单个文件映射到路由   g.StaticFile("/favicon.ico", "./public/favicon.ico")
+func (r *routerGroup) StaticFile(name, file string) {
+	s := &staticServer{fs: http.Dir(filepath.Dir(file))}
+	base := "/" + filepath.Base(file)
+	r.Get(name, func(ctx *Context) {
+		s.serveFile(ctx, base, true)
+	})
+}
+
+type staticServer struct {
+	fs     http.FileSystem
+	config StaticConfig
+	//没有修改时间的文件(embed.FS)按内容计算ETag  key为文件名
+	etags sync.Map
+}
+
+func (s *staticServer) serve(ctx *Context) {
+	name := ctx.CatchAll()
+	//防止通过 .. 访问目录之外的文件
+	if !validStaticPath(name) {
+		ctx.Fail(http.StatusBadRequest, "invalid path")
+		return
+	}
+	s.serveFile(ctx, path.Clean("/"+name), false)
+}
+
+func validStaticPath(name string) bool {
+	if strings.ContainsAny(name, "\\\x00") {
+		return false
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *staticServer) serveFile(ctx *Context, name string, single bool) {
+	f, err := s.fs.Open(name)
+	if err != nil {
+		s.notFound(ctx)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		s.notFound(ctx)
+		return
+	}
+	if info.IsDir() && !single {
+		//目录需要以 / 结尾，保证页面中的相对路径正确
+		if !strings.HasSuffix(ctx.R.URL.Path, "/") {
+			//与http.FileServer相同使用相对路径  //evil.com 不能重定向到 //evil.com/ (其他网站)
+			//http.Redirect会把相对路径转化为绝对路径，这里直接设置Location
+			location := "./" + (&url.URL{Path: path.Base(ctx.R.URL.Path)}).EscapedPath() + "/"
+			if ctx.R.URL.RawQuery != "" {
+				location += "?" + ctx.R.URL.RawQuery
+			}
+			ctx.W.Header().Set("Location", location)
+			ctx.W.WriteHeader(http.StatusMovedPermanently)
+			ctx.StatusCode = http.StatusMovedPermanently
+			return
+		}
+		index, err := s.fs.Open(path.Join(name, "index.html"))
+		if err == nil {
+			defer index.Close()
+			if indexInfo, err := index.Stat(); err == nil && !indexInfo.IsDir() {
+				s.serveContent(ctx, path.Join(name, "index.html"), indexInfo, index)
+				return
+			}
+		}
+		if s.config.ListDirectory {
+			s.listDirectory(ctx, f)
+			return
+		}
+		s.notFound(ctx)
+		return
+	}
+	if info.IsDir() {
+		s.notFound(ctx)
+		return
+	}
+	s.serveContent(ctx, name, info, f)
+}
+
+//http.ServeContent 处理Range、If-Modified-Since、If-None-Match
+func (s *staticServer) serveContent(ctx *Context, name string, info os.FileInfo, f http.File) {
+	if etag := s.etag(name, info, f); etag != "" {
+		ctx.W.Header().Set("ETag", etag)
+	}
+	ctx.StatusCode = http.StatusOK
+	http.ServeContent(ctx.W, ctx.R, info.Name(), info.ModTime(), f)
+}
+
+//有修改时间时由修改时间和大小生成，否则按内容计算并缓存
+func (s *staticServer) etag(name string, info os.FileInfo, f http.File) string {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+	}
+	if etag, ok := s.etags.Load(name); ok {
+		return etag.(string)
+	}
+	h := fnv.New64a()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ""
+	}
+	etag := fmt.Sprintf(`"%x-%x"`, h.Sum64(), info.Size())
+	s.etags.Store(name, etag)
+	return etag
+}
+
+func (s *staticServer) listDirectory(ctx *Context, dir http.File) {
+	infos, err := dir.Readdir(-1)
+	if err != nil {
+		ctx.Fail(http.StatusInternalServerError, "read directory failed")
+		return
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	var sb strings.Builder
+	sb.WriteString("<pre>\n")
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: name}
+		fmt.Fprintf(&sb, "<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(name))
+	}
+	sb.WriteString("</pre>\n")
+	ctx.HTML(http.StatusOK, sb.String())
+}
+
+//文件不存在  配置了Fallback时返回Fallback，否则交给NoRoute处理
+func (s *staticServer) notFound(ctx *Context) {
+	if s.config.Fallback != "" {
+		if f, err := s.fs.Open(s.config.Fallback); err == nil {
+			defer f.Close()
+			if info, err := f.Stat(); err == nil && !info.IsDir() {
+				s.serveContent(ctx, s.config.Fallback, info, f)
+				return
+			}
+		}
+	}
+	if ctx.engine != nil && ctx.engine.noRoute != nil {
+		ctx.engine.noRoute(ctx)
+		return
+	}
+	http.NotFound(ctx.W, ctx.R)
+}
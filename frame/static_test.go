@@ -0,0 +1,104 @@
+package frame
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRouterGroupStatic(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "css"), 0755)
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "css", "a.css"), []byte("body{}"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("docs"), 0644)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644)
+
+	engine := New()
+	g := engine.Group("user")
+	g.Static("/assets", filepath.Join(dir, "css"), StaticConfig{ListDirectory: true})
+	g.Static("/site", dir)
+	g.StaticFile("/secret", filepath.Join(dir, "secret.txt"))
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/user/assets/a.css", http.StatusOK, "body{}"},
+		{"/user/assets/", http.StatusOK, "<pre>\n<a href=\"a.css\">a.css</a>\n</pre>\n"},
+		{"/user/assets/../secret.txt", http.StatusBadRequest, ""},
+		{"/user/site/docs/", http.StatusOK, "docs"},
+		{"/user/site/docs", http.StatusMovedPermanently, ""},
+		{"/user/site/css/", http.StatusNotFound, ""},
+		{"/user/site/none.js", http.StatusNotFound, ""},
+		{"/user/secret", http.StatusOK, "secret"},
+	}
+	for _, tt := range tests {
+		w := performRequest(engine, http.MethodGet, tt.path)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+
+	w := performRequest(engine, http.MethodGet, "/user/site/docs?v=1")
+	if location := w.Header().Get("Location"); location != "./docs/?v=1" {
+		t.Errorf("/user/site/docs: Location = %q, want ./docs/?v=1", location)
+	}
+
+	//目录重定向不能指向其他网站
+	os.MkdirAll(filepath.Join(dir, "evil.com"), 0755)
+	root := New()
+	root.Group("").StaticFS("/", http.Dir(dir))
+	w = performRequest(root, http.MethodGet, "//evil.com")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "./evil.com/" {
+		t.Errorf("//evil.com: got %d Location %q, want 301 ./evil.com/", w.Code, w.Header().Get("Location"))
+	}
+
+	w = performRequest(engine, http.MethodGet, "/user/assets/a.css")
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("missing ETag or Last-Modified: %v", w.Header())
+	}
+	r := httptest.NewRequest(http.MethodGet, "/user/assets/a.css", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: code = %d, want 304", w.Code)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/user/assets/a.css", nil)
+	r.Header.Set("Range", "bytes=0-3")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "body" {
+		t.Errorf("Range: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouterGroupStaticFSFallback(t *testing.T) {
+	//embed.FS同样通过http.FS使用
+	fsys := fstest.MapFS{
+		"index.html":  {Data: []byte("app")},
+		"static/a.js": {Data: []byte("js")},
+	}
+	engine := New()
+	engine.Group("").StaticFS("/", http.FS(fsys), StaticConfig{Fallback: "/index.html"})
+
+	for path, body := range map[string]string{
+		"/":            "app",
+		"/static/a.js": "js",
+		"/user/12":     "app",
+	} {
+		w := performRequest(engine, http.MethodGet, path)
+		if w.Code != http.StatusOK || w.Body.String() != body {
+			t.Errorf("%s: got %d %q, want %q", path, w.Code, w.Body.String(), body)
+		}
+		if w.Header().Get("ETag") == "" {
+			t.Errorf("%s: missing ETag", path)
+		}
+	}
+}
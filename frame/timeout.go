@@ -0,0 +1,221 @@
+package frame
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//Context实现了context.Context  可以直接传给数据库查询等需要context的函数
+//客户端断开连接或超时时Done()关闭
+var _ context.Context = (*Context)(nil)
+
+func (ctx *Context) Deadline() (deadline time.Time, ok bool) {
+	if ctx.R == nil {
+		return
+	}
+	return ctx.R.Context().Deadline()
+}
+
+func (ctx *Context) Done() <-chan struct{} {
+	if ctx.R == nil {
+		return nil
+	}
+	return ctx.R.Context().Done()
+}
+
+func (ctx *Context) Err() error {
+	if ctx.R == nil {
+		return nil
+	}
+	return ctx.R.Context().Err()
+}
+
+//key为string时先从Keys中查找，找不到时从请求的context中查找
+func (ctx *Context) Value(key any) any {
+	if k, ok := key.(string); ok {
+		if value, exists := ctx.Get(k); exists {
+			return value
+		}
+	}
+	if ctx.R == nil {
+		return nil
+	}
+	return ctx.R.Context().Value(key)
+}
+
+//设置路由的超时时间   g.Get("/report", h).Timeout(3 * time.Second)
+//超时后取消请求的context，返回503
+func (rt *Route) Timeout(d time.Duration) *Route {
+	rt.timeout = d
+	rt.group.router.engine.changed()
+	return rt
+}
+
+//超时中间件  超过d时取消请求的context并返回503   g.UseHandler(frame.Timeout(5 * time.Second))
+//后续处理器在新的goroutine中执行，响应先写入缓冲，处理完成后再发送给客户端
+//超时后处理器写入的内容被丢弃，当前goroutine等待处理器返回后才结束请求，处理器应当通过ctx.Done()及时退出
+//ctx.W和ctx.Writer都替换为缓冲，Flush不会发送给客户端，不支持Hijack
+func Timeout(d time.Duration) HandlerFunc {
+	return func(ctx *Context) {
+		c, cancel := context.WithTimeout(ctx.R.Context(), d)
+		defer cancel()
+		w, writer, r := ctx.W, ctx.Writer, ctx.R
+		tw := &timeoutWriter{w: w, header: make(http.Header), code: http.StatusOK}
+		ctx.W = tw
+		ctx.Writer = tw
+		ctx.R = r.WithContext(c)
+		defer func() {
+			ctx.W = w
+			ctx.Writer = writer
+			ctx.R = r
+		}()
+
+		done := make(chan struct{})
+		panicChan := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+				close(done)
+			}()
+			ctx.Next()
+		}()
+		select {
+		case <-done:
+		case <-c.Done():
+			tw.timeout()
+			<-done
+		}
+		select {
+		case p := <-panicChan:
+			//交给外层的Recovery处理
+			panic(p)
+		default:
+		}
+		if tw.timedOut {
+			ctx.StatusCode = http.StatusServiceUnavailable
+			return
+		}
+		tw.flush()
+	}
+}
+
+//缓存处理器的响应  超时后丢弃
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	code   int
+	//处理器是否已经写入响应  Written()的结果
+	wrote bool
+	//超时后不再写入
+	timedOut bool
+}
+
+var _ ResponseWriter = (*timeoutWriter)(nil)
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wrote = true
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	return tw.Write([]byte(s))
+}
+
+//与responseWriter相同  写入响应体前以最后一次为准
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wrote || code <= 0 {
+		return
+	}
+	tw.code = code
+}
+
+func (tw *timeoutWriter) WriteHeaderNow() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.timedOut {
+		tw.wrote = true
+	}
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.code
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.wrote {
+		return noWritten
+	}
+	return tw.buf.Len()
+}
+
+func (tw *timeoutWriter) Written() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.wrote
+}
+
+//响应在处理完成后才发送  这里只标记为已写入
+func (tw *timeoutWriter) Flush() {
+	tw.WriteHeaderNow()
+}
+
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("the ResponseWriter does not support hijacking in Timeout")
+}
+
+func (tw *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+//处理完成  将缓存的响应发送给客户端
+func (tw *timeoutWriter) flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	dst := tw.w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	tw.w.WriteHeader(tw.code)
+	_, _ = tw.w.Write(tw.buf.Bytes())
+}
+
+//超时  立即返回503，不等待处理器返回
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.timedOut = true
+	body := http.StatusText(http.StatusServiceUnavailable)
+	tw.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	tw.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	tw.w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = tw.w.Write([]byte(body))
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
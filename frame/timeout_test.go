@@ -0,0 +1,125 @@
+package frame
+
+import (
+	"context"
+	"errors"
+	jplog "github.com/NBjjp/JpWebFrame/log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestContextAsContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	parent, cancel := context.WithCancel(context.WithValue(r.Context(), ctxKey{}, "request"))
+	ctx := &Context{R: r.WithContext(parent)}
+	ctx.Set("user", "jp")
+
+	var c context.Context = ctx
+	if v := c.Value("user"); v != "jp" {
+		t.Errorf("Value(user) = %v, want jp", v)
+	}
+	if v := c.Value(ctxKey{}); v != "request" {
+		t.Errorf("Value(ctxKey) = %v, want request", v)
+	}
+	if _, ok := c.Deadline(); ok {
+		t.Error("Deadline set without timeout")
+	}
+	if c.Err() != nil {
+		t.Errorf("Err = %v before cancel", c.Err())
+	}
+	cancel()
+	<-c.Done()
+	if !errors.Is(c.Err(), context.Canceled) {
+		t.Errorf("Err = %v, want %v", c.Err(), context.Canceled)
+	}
+}
+
+func TestRouteTimeout(t *testing.T) {
+	engine := New()
+	var status int
+	engine.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) {
+			next(ctx)
+			status = ctx.StatusCode
+		}
+	})
+	g := engine.Group("")
+	canceled := make(chan error, 1)
+	g.Get("/slow", func(ctx *Context) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("no deadline in route with timeout")
+		}
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		ctx.String(http.StatusOK, "too late")
+	}).Timeout(20 * time.Millisecond)
+	g.Get("/fast", func(ctx *Context) {
+		ctx.W.Header().Set("X-Fast", "1")
+		ctx.String(http.StatusCreated, "fast")
+	}).Timeout(time.Second)
+
+	w := performRequest(engine, http.MethodGet, "/slow")
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "Service Unavailable" {
+		t.Errorf("/slow = %d %q, want 503", w.Code, w.Body.String())
+	}
+	if err := <-canceled; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("handler ctx.Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode seen by middleware = %d, want 503", status)
+	}
+
+	w = performRequest(engine, http.MethodGet, "/fast")
+	if w.Code != http.StatusCreated || w.Body.String() != "fast" || w.Header().Get("X-Fast") != "1" {
+		t.Errorf("/fast = %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestTimeoutPanicRecovered(t *testing.T) {
+	engine := New()
+	engine.Use(Recovery)
+	engine.Logger = jplog.Default()
+	engine.Logger.Outs = nil
+	g := engine.Group("")
+	g.UseHandler(Timeout(time.Second))
+	g.Get("/panic", func(ctx *Context) {
+		panic(errors.New("boom"))
+	})
+	w := performRequest(engine, http.MethodGet, "/panic")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d, want 500", w.Code)
+	}
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	engine := New()
+	g := engine.Group("")
+	type state struct {
+		before, after bool
+		size          int
+		status        int
+	}
+	states := make(chan state, 1)
+	g.Get("/written", func(ctx *Context) {
+		var st state
+		st.before = ctx.Writer.Written()
+		ctx.Writer.WriteHeader(http.StatusAccepted)
+		_, _ = ctx.Writer.Write([]byte("hello"))
+		ctx.Writer.Flush()
+		st.after, st.size, st.status = ctx.Writer.Written(), ctx.Writer.Size(), ctx.Writer.Status()
+		states <- st
+	}).Timeout(time.Second)
+
+	w := performRequest(engine, http.MethodGet, "/written")
+	st := <-states
+	if st.before || !st.after || st.size != 5 || st.status != http.StatusAccepted {
+		t.Errorf("in timeout route: %+v", st)
+	}
+	if w.Code != http.StatusAccepted || w.Body.String() != "hello" {
+		t.Errorf("/written = %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,189 @@
+package frame
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+//检查证书文件是否修改的默认间隔
+const defaultCertReloadInterval = 10 * time.Second
+
+//https配置
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	//检查证书文件是否修改的间隔  默认10秒
+	//证书文件修改后在下一次握手时重新加载，不需要重启服务
+	ReloadInterval time.Duration
+	//客户端证书的CA  设置后开启双向认证(mTLS)
+	ClientCAFile string
+	//校验客户端证书的方式  设置了ClientCAFile时默认tls.RequireAndVerifyClientCert
+	ClientAuth tls.ClientAuthType
+}
+
+//证书文件修改后自动重新加载   用于tls.Config.GetCertificate
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	checkedAt time.Time
+}
+
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (*CertReloader, error) {
+	if interval <= 0 {
+		interval = defaultCertReloadInterval
+	}
+	c := &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//重新加载证书  加载失败时继续使用原来的证书
+func (c *CertReloader) Reload() error {
+	certMod, keyMod, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cert = &cert
+	c.certMod = certMod
+	c.keyMod = keyMod
+	c.checkedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *CertReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+//每次握手时调用  超过检查间隔时比较文件修改时间，修改过则重新加载
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	cert := c.cert
+	expired := time.Since(c.checkedAt) >= c.interval
+	c.mu.RUnlock()
+	if !expired {
+		return cert, nil
+	}
+	c.mu.Lock()
+	if time.Since(c.checkedAt) < c.interval {
+		cert = c.cert
+		c.mu.Unlock()
+		return cert, nil
+	}
+	c.checkedAt = time.Now()
+	certMod, keyMod, err := c.modTimes()
+	changed := err == nil && (!certMod.Equal(c.certMod) || !keyMod.Equal(c.keyMod))
+	c.mu.Unlock()
+	if changed {
+		//证书和私钥可能还没有都写完，加载失败时下次再试
+		_ = c.Reload()
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+//按TLSOptions创建tls.Config
+func (o TLSOptions) Config() (*tls.Config, error) {
+	reloader, err := NewCertReloader(o.CertFile, o.KeyFile, o.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		ClientAuth:     o.ClientAuth,
+	}
+	if o.ClientCAFile != "" {
+		pem, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", o.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		if config.ClientAuth == tls.NoClientCert {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return config, nil
+}
+
+//启动https服务  证书文件修改后自动重新加载，可选双向认证
+//关闭方式与RunContext相同
+func (e *Engine) RunTLSOptions(ctx context.Context, addr string, opts TLSOptions) error {
+	config, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	srv := e.newServer(addr)
+	srv.TLSConfig = config
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return e.serve(ctx, srv, ln, func(ln net.Listener) error {
+		return srv.ServeTLS(ln, "", "")
+	})
+}
+
+//支持不加密的HTTP/2(h2c)  客户端直接发送HTTP/2请求或通过Upgrade: h2c升级
+func enableH2C(srv *http.Server) {
+	h2s := &http2.Server{IdleTimeout: srv.IdleTimeout}
+	//关闭时同时通知HTTP/2连接
+	_ = http2.ConfigureServer(srv, h2s)
+	srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+}
+
+//经过校验的客户端证书  没有开启双向认证或客户端没有提供证书时返回nil
+func (ctx *Context) ClientCert() *x509.Certificate {
+	if ctx.R.TLS == nil || len(ctx.R.TLS.VerifiedChains) == 0 || len(ctx.R.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return ctx.R.TLS.VerifiedChains[0][0]
+}
+
+//客户端证书中的身份  优先使用URI(spiffe://...)，其次是CommonName
+func (ctx *Context) PeerIdentity() (string, error) {
+	cert := ctx.ClientCert()
+	if cert == nil {
+		return "", errors.New("no verified client certificate")
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String(), nil
+	}
+	return cert.Subject.CommonName, nil
+}
@@ -0,0 +1,234 @@
+package frame
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"golang.org/x/net/http2"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	kpem []byte
+}
+
+//生成测试证书  parent为nil时生成自签名的CA
+func newTestCert(t *testing.T, serial int64, cn string, parent *testCert, uris ...string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	for _, u := range uris {
+		parsed, _ := url.Parse(u)
+		tmpl.URIs = append(tmpl.URIs, parsed)
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		kpem: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string, mod time.Time) {
+	t.Helper()
+	if err := os.WriteFile(certFile, c.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, c.kpem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chtimes(certFile, mod, mod)
+	_ = os.Chtimes(keyFile, mod, mod)
+}
+
+func startTLSEngine(t *testing.T, e *Engine, opts TLSOptions) string {
+	t.Helper()
+	config, err := opts.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := e.newServer(ln.Addr().String())
+	srv.TLSConfig = config
+	go func() {
+		_ = e.serve(context.Background(), srv, ln, func(ln net.Listener) error {
+			return srv.ServeTLS(ln, "", "")
+		})
+	}()
+	t.Cleanup(func() {
+		_ = e.Shutdown(context.Background())
+	})
+	return ln.Addr().String()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	ca := newTestCert(t, 1, "ca", nil)
+	newTestCert(t, 2, "server", ca).write(t, certFile, keyFile, time.Now().Add(-time.Minute))
+
+	engine := New()
+	addr := startTLSEngine(t, engine, TLSOptions{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Millisecond})
+	serial := func() int64 {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+	if s := serial(); s != 2 {
+		t.Fatalf("serial = %d, want 2", s)
+	}
+	newTestCert(t, 3, "server", ca).write(t, certFile, keyFile, time.Now())
+	time.Sleep(5 * time.Millisecond)
+	if s := serial(); s != 3 {
+		t.Errorf("serial after reload = %d, want 3", s)
+	}
+
+	//证书文件损坏时继续使用原来的证书
+	if err := os.WriteFile(certFile, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if s := serial(); s != 3 {
+		t.Errorf("serial with broken file = %d, want 3", s)
+	}
+}
+
+func TestEngineMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := newTestCert(t, 1, "ca", nil)
+	newTestCert(t, 2, "server", ca).write(t, certFile, keyFile, time.Now())
+	if err := os.WriteFile(caFile, ca.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	client := newTestCert(t, 3, "order-service", ca, "spiffe://example.com/order")
+
+	engine := New()
+	engine.Group("").Get("/whoami", func(ctx *Context) {
+		id, err := ctx.PeerIdentity()
+		if err != nil {
+			ctx.String(http.StatusUnauthorized, err.Error())
+			return
+		}
+		ctx.String(http.StatusOK, "%s %s", id, ctx.ClientCert().Subject.CommonName)
+	})
+	addr := startTLSEngine(t, engine, TLSOptions{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert := tls.Certificate{Certificate: [][]byte{client.cert.Raw}, PrivateKey: client.key}
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	resp, err := httpClient.Get("https://" + addr + "/whoami")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "spiffe://example.com/order order-service" {
+		t.Errorf("body = %q", body)
+	}
+
+	//没有客户端证书时握手失败
+	noCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if resp, err := noCert.Get("https://" + addr + "/whoami"); err == nil {
+		resp.Body.Close()
+		t.Error("request without client certificate succeeded")
+	}
+}
+
+func TestEngineH2C(t *testing.T) {
+	engine := New()
+	engine.ServerOptions.H2C = true
+	engine.Group("").Get("/proto", func(ctx *Context) {
+		ctx.String(http.StatusOK, ctx.R.Proto)
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := engine.newServer(ln.Addr().String())
+	go func() {
+		_ = engine.serve(context.Background(), srv, ln, srv.Serve)
+	}()
+	defer engine.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + ln.Addr().String() + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/2.0" {
+		t.Errorf("proto = %q, want HTTP/2.0", body)
+	}
+
+	//HTTP/1.1请求不受影响
+	resp, err = http.Get("http://" + ln.Addr().String() + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/1.1" {
+		t.Errorf("proto = %q, want HTTP/1.1", body)
+	}
+}
@@ -1,82 +1,330 @@
 package frame
 
 import (
+	"fmt"
 	"strings"
 )
 
+//节点类型  数值越小优先级越高   静态 > :param > * > **
+type nodeKind uint8
+
+const (
+	staticKind nodeKind = iota
+	paramKind
+	wildcardKind
+	catchAllKind
+)
+
+func segmentKind(name string) nodeKind {
+	switch {
+	case name == catchAllKey:
+		return catchAllKind
+	case name == wildcardKey:
+		return wildcardKind
+	case strings.HasPrefix(name, ":"):
+		return paramKind
+	default:
+		return staticKind
+	}
+}
+
+//压缩前缀树(radix tree)
+//静态节点存放压缩后的公共前缀，如 /user/get 和 /user/list 共用 /user/ 节点
+//:param * ** 各自单独成为一个节点，挂在以 / 结尾的静态节点下
+//树只在注册路由时修改，查找时只读，可以被多个请求并发查找
 type treeNode struct {
-	name       string
-	children   []*treeNode
+	//静态节点为公共前缀，参数节点为 :id，通配节点为 * 或 **
+	name string
+	kind nodeKind
+	//静态子节点的首字母  与children一一对应
+	indices  string
+	children []*treeNode
+	//参数子节点  带约束的在前，不带约束的最多一个且在最后
+	paramChildren []*treeNode
+	//* ** 子节点  最多各一个
+	wildcardChild *treeNode
+	catchAllChild *treeNode
+	//参数名  :id 对应 id
+	paramKey string
+	//参数约束  :id<int>
+	constraint *paramConstraint
+	//路由结束节点上按参数顺序存放的约束  用于获取转化后的参数值
+	constraints []*paramConstraint
+	//注册时的完整路由   /user/get/:id   注册后不再修改
 	routerName string
 	isEnd      bool
+	//该路由按请求方式注册的处理器  key为GET POST ANY等
+	routes map[string]*Route
+	//该路由支持的请求方式  GET, HEAD, OPTIONS
+	allow string
 }
 
 //put   path:   /user/get/:id
-func (t *treeNode) Put(path string) {
-	root := t
-	strs := strings.Split(path, "/")
-	for index, name := range strs {
-		if index == 0 {
+//根节点自身的name不参与匹配   返回路由对应的节点
+func (t *treeNode) Put(path string) *treeNode {
+	if path == "" || path[0] != '/' {
+		panic(fmt.Sprintf("路由 %s 必须以 / 开头", path))
+	}
+	n := t
+	rest := path
+	var constraints []*paramConstraint
+	hasConstraint := false
+	for rest != "" {
+		i := nextDynamic(rest)
+		if i > 0 {
+			n = n.putStatic(rest[:i])
+			rest = rest[i:]
+		}
+		if rest == "" {
+			break
+		}
+		end := strings.IndexByte(rest, '/')
+		if end < 0 {
+			end = len(rest)
+		}
+		n = n.putDynamic(path, rest[:end])
+		constraints = append(constraints, n.constraint)
+		if n.constraint != nil {
+			hasConstraint = true
+		}
+		rest = rest[end:]
+		if n.kind == catchAllKind && rest != "" {
+			panic(fmt.Sprintf("路由 %s 中 ** 只能出现在最后", path))
+		}
+	}
+	n.isEnd = true
+	n.routerName = path
+	if hasConstraint {
+		n.constraints = constraints
+	}
+	return n
+}
+
+//返回下一个动态段(:param * **)的起始位置，没有则返回len(s)
+func nextDynamic(s string) int {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] != '/' {
 			continue
 		}
-		children := t.children
-		isMatch := false
-		for _, node := range children {
-			if node.name == name {
-				isMatch = true
-				t = node
+		end := strings.IndexByte(s[i+1:], '/')
+		if end < 0 {
+			end = len(s) - i - 1
+		}
+		seg := s[i+1 : i+1+end]
+		if segmentKind(seg) != staticKind {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+//插入静态路径  与已有子节点的公共前缀不足时拆分子节点
+func (t *treeNode) putStatic(s string) *treeNode {
+	n := t
+	for s != "" {
+		var child *treeNode
+		for i := 0; i < len(n.indices); i++ {
+			if n.indices[i] == s[0] {
+				child = n.children[i]
 				break
 			}
 		}
-		if !isMatch {
-			isEnd := false
-			if index == len(strs)-1 {
-				isEnd = true
+		if child == nil {
+			child = &treeNode{name: s, kind: staticKind}
+			n.indices += string(s[0])
+			n.children = append(n.children, child)
+			return child
+		}
+		l := longestCommonPrefix(s, child.name)
+		if l < len(child.name) {
+			//拆分  原节点保留公共前缀，剩余部分连同原节点的子节点下移
+			split := *child
+			split.name = child.name[l:]
+			*child = treeNode{
+				name:     child.name[:l],
+				kind:     staticKind,
+				indices:  string(split.name[0]),
+				children: []*treeNode{&split},
+			}
+		}
+		s = s[l:]
+		n = child
+	}
+	return n
+}
+
+//插入 :param * ** 节点
+func (t *treeNode) putDynamic(path, seg string) *treeNode {
+	switch segmentKind(seg) {
+	case paramKind:
+		key, constraint := parseParamSegment(path, seg)
+		if key == "" {
+			panic(fmt.Sprintf("路由 %s 中参数名不能为空", path))
+		}
+		for _, child := range t.paramChildren {
+			if child.name == seg {
+				return child
+			}
+			//同一位置约束相同时只能有一个参数名，否则 /user/:id 和 /user/:name 无法区分
+			if child.constraint.String() == constraint.String() {
+				panic(fmt.Sprintf("路由 %s 中的 %s 与已存在的 %s 冲突", path, seg, child.name))
 			}
-			node := &treeNode{
-				name:     name,
-				children: make([]*treeNode, 0),
-				isEnd:    isEnd,
+		}
+		child := &treeNode{name: seg, kind: paramKind, paramKey: key, constraint: constraint}
+		//带约束的参数优先匹配
+		i := len(t.paramChildren)
+		if constraint != nil {
+			for i > 0 && t.paramChildren[i-1].constraint == nil {
+				i--
 			}
-			children = append(children, node)
-			t.children = children
-			t = node
 		}
+		t.paramChildren = append(t.paramChildren, nil)
+		copy(t.paramChildren[i+1:], t.paramChildren[i:])
+		t.paramChildren[i] = child
+		return child
+	case wildcardKind:
+		if t.wildcardChild == nil {
+			t.wildcardChild = &treeNode{name: seg, kind: wildcardKind, paramKey: wildcardKey}
+		}
+		return t.wildcardChild
+	default:
+		if t.catchAllChild == nil {
+			t.catchAllChild = &treeNode{name: seg, kind: catchAllKind, paramKey: catchAllKey}
+		}
+		return t.catchAllChild
 	}
-	t = root
 }
 
-//get path:/user/get/1
-func (t *treeNode) Get(path string) *treeNode {
-	strs := strings.Split(path, "/")
-	routerName := ""
-	for index, name := range strs {
-		if index == 0 {
+func longestCommonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+//get path:/user/get/1    匹配到的路由参数追加到params中
+//params容量足够时查找过程不分配内存
+func (t *treeNode) Get(path string, params *Params) *treeNode {
+	return t.getValue(path, params)
+}
+
+//t已匹配完成，path为剩余路径
+//按 静态 > :param > * > ** 的优先级匹配，更具体的分支匹配失败时回溯到下一个分支
+func (t *treeNode) getValue(path string, params *Params) *treeNode {
+	if path == "" {
+		if t.isEnd {
+			return t
+		}
+		// /static/** 可以匹配 /static/
+		if child := t.catchAllChild; child != nil && child.isEnd {
+			*params = append(*params, Param{Key: child.paramKey})
+			return child
+		}
+		return nil
+	}
+	for i := 0; i < len(t.indices); i++ {
+		if t.indices[i] != path[0] {
 			continue
 		}
-		children := t.children
-		isMatch := false
-		for _, node := range children {
-			if node.name == name || node.name == "*" || strings.Contains(node.name, ":") {
-				isMatch = true
-				routerName += "/" + node.name
-				node.routerName = routerName
-				t = node
-				if index == len(strs)-1 {
-					return node
-				}
-				break
+		child := t.children[i]
+		if strings.HasPrefix(path, child.name) {
+			if n := child.getValue(path[len(child.name):], params); n != nil {
+				return n
 			}
 		}
-		if !isMatch {
-			for _, node := range children {
-				if node.name == "**" {
-					routerName += "/" + node.name
-					node.routerName = routerName
-					return node
+		break
+	}
+	//动态子节点只挂在以 / 结尾的节点下，此时path一定处于一段路径的开头
+	if len(t.paramChildren) > 0 || t.wildcardChild != nil {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			value := path[:end]
+			for _, child := range t.paramChildren {
+				if child.constraint != nil && !child.constraint.match(value) {
+					continue
+				}
+				if n := child.getDynamic(value, path[end:], params); n != nil {
+					return n
+				}
+			}
+			if child := t.wildcardChild; child != nil {
+				if n := child.getDynamic(value, path[end:], params); n != nil {
+					return n
 				}
 			}
 		}
 	}
+	if child := t.catchAllChild; child != nil && child.isEnd {
+		//剩余的全部路径
+		*params = append(*params, Param{Key: child.paramKey, Value: path})
+		return child
+	}
 	return nil
 }
+
+//参数节点匹配value后继续匹配剩余路径  失败时撤销添加的参数
+func (t *treeNode) getDynamic(value, path string, params *Params) *treeNode {
+	k := len(*params)
+	*params = append(*params, Param{Key: t.paramKey, Value: value})
+	if n := t.getValue(path, params); n != nil {
+		return n
+	}
+	*params = (*params)[:k]
+	return nil
+}
+
+//路由中参数的个数  用于提前分配Params的容量
+func countParams(path string) int {
+	n := 0
+	for _, seg := range strings.Split(path, "/") {
+		if segmentKind(seg) != staticKind {
+			n++
+		}
+	}
+	return n
+}
+
+//忽略大小写查找路由  返回注册的静态部分与请求中参数值拼接成的路径
+//只在重定向时使用，不要求零分配
+func (t *treeNode) findCaseInsensitive(path string, buf []byte) ([]byte, bool) {
+	if path == "" {
+		if t.isEnd {
+			return buf, true
+		}
+		if child := t.catchAllChild; child != nil && child.isEnd {
+			return buf, true
+		}
+		return nil, false
+	}
+	for _, child := range t.children {
+		if len(path) >= len(child.name) && strings.EqualFold(path[:len(child.name)], child.name) {
+			if fixed, ok := child.findCaseInsensitive(path[len(child.name):], append(buf, child.name...)); ok {
+				return fixed, true
+			}
+		}
+	}
+	if len(t.paramChildren) > 0 || t.wildcardChild != nil {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			for _, child := range append(t.paramChildren[:len(t.paramChildren):len(t.paramChildren)], t.wildcardChild) {
+				if child == nil || (child.constraint != nil && !child.constraint.match(path[:end])) {
+					continue
+				}
+				if fixed, ok := child.findCaseInsensitive(path[end:], append(buf, path[:end]...)); ok {
+					return fixed, true
+				}
+			}
+		}
+	}
+	if child := t.catchAllChild; child != nil && child.isEnd {
+		return append(buf, path...), true
+	}
+	return nil, false
+}
@@ -2,26 +2,285 @@ package frame
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestTreeNode(t *testing.T) {
 	root := &treeNode{
-		name:     "/",
-		children: make([]*treeNode, 0),
+		name: "/",
 	}
 	root.Put("/user/get/:id")
 	root.Put("/user/create/dd")
 	root.Put("/user/create/id")
 	root.Put("/order/get/dd")
 
-	node := root.Get("/user/get/1")
+	var params Params
+	node := root.Get("/user/get/1", &params)
 	fmt.Println(node)
-	node = root.Get("/user/create/dd")
+	node = root.Get("/user/create/dd", &params)
 	fmt.Println(node)
-	node = root.Get("/user/create")
+	node = root.Get("/user/create", &params)
 	fmt.Println(node)
-	node = root.Get("/order/get/dd")
+	node = root.Get("/order/get/dd", &params)
 	fmt.Println(node)
 
 }
+
+func TestTreeNodeParams(t *testing.T) {
+	root := &treeNode{name: "/"}
+	root.Put("/user/get/:id")
+	root.Put("/file/*/name")
+	root.Put("/static/**")
+
+	tests := []struct {
+		path   string
+		params Params
+	}{
+		{"/user/get/1", Params{{Key: "id", Value: "1"}}},
+		{"/file/abc/name", Params{{Key: "*", Value: "abc"}}},
+		{"/static/css/a.css", Params{{Key: "**", Value: "css/a.css"}}},
+		{"/static/", Params{{Key: "**", Value: ""}}},
+	}
+	for _, tt := range tests {
+		var params Params
+		node := root.Get(tt.path, &params)
+		if node == nil {
+			t.Fatalf("%s: route not found", tt.path)
+		}
+		if fmt.Sprint(params) != fmt.Sprint(tt.params) {
+			t.Errorf("%s: params = %v, want %v", tt.path, params, tt.params)
+		}
+	}
+}
+
+func TestTreeNodePriority(t *testing.T) {
+	root := &treeNode{name: "/"}
+	root.Put("/user/:id")
+	root.Put("/user/me")
+	root.Put("/user/:id/profile")
+	root.Put("/user/*/settings")
+	root.Put("/user/**")
+	root.Put("/user/me/avatar")
+	root.Put("/users")
+
+	tests := []struct {
+		path       string
+		routerName string
+	}{
+		{"/user/me", "/user/me"},
+		{"/user/12", "/user/:id"},
+		{"/user/meow", "/user/:id"},
+		{"/users", "/users"},
+		{"/user/me/avatar", "/user/me/avatar"},
+		//静态分支 /user/me 匹配失败后回溯到 :id
+		{"/user/me/profile", "/user/:id/profile"},
+		{"/user/me/settings", "/user/*/settings"},
+		{"/user/me/a/b", "/user/**"},
+	}
+	for _, tt := range tests {
+		var params Params
+		node := root.Get(tt.path, &params)
+		if node == nil {
+			t.Errorf("%s: route not found", tt.path)
+			continue
+		}
+		if node.routerName != tt.routerName {
+			t.Errorf("%s: matched %s, want %s", tt.path, node.routerName, tt.routerName)
+		}
+	}
+	var params Params
+	if node := root.Get("/user", &params); node != nil {
+		t.Errorf("/user: matched %s, want nil", node.routerName)
+	}
+}
+
+func TestTreeNodeAmbiguous(t *testing.T) {
+	root := &treeNode{name: "/"}
+	root.Put("/user/:id")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for ambiguous routes")
+		}
+	}()
+	root.Put("/user/:name")
+}
+
+func TestTreeNodeConcurrentGet(t *testing.T) {
+	root := &treeNode{name: "/"}
+	for _, route := range benchRoutes {
+		root.Put(route)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params := make(Params, 0, 4)
+			for j := 0; j < 1000; j++ {
+				params = params[:0]
+				if node := root.Get("/user/12/orders/34", &params); node == nil || node.routerName != "/user/:id/orders/:oid" {
+					t.Error("unexpected match")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTreeNodeGetNoAlloc(t *testing.T) {
+	root := &treeNode{name: "/"}
+	for _, route := range benchRoutes {
+		root.Put(route)
+	}
+	params := make(Params, 0, 4)
+	allocs := testing.AllocsPerRun(100, func() {
+		params = params[:0]
+		root.Get("/user/12/orders/34", &params)
+		params = params[:0]
+		root.Get("/static/js/app.js", &params)
+	})
+	if allocs != 0 {
+		t.Errorf("Get allocs = %v, want 0", allocs)
+	}
+}
+
+var benchRoutes = []string{
+	"/",
+	"/user/login",
+	"/user/logout",
+	"/user/me",
+	"/user/:id",
+	"/user/:id/profile",
+	"/user/:id/orders",
+	"/user/:id/orders/:oid",
+	"/order/list",
+	"/order/get/:id",
+	"/order/*/items",
+	"/goods/category/:cid/list",
+	"/static/**",
+}
+
+var benchPaths = []string{
+	"/user/login",
+	"/user/12/orders/34",
+	"/order/get/99",
+	"/goods/category/7/list",
+	"/static/js/app.js",
+}
+
+func BenchmarkTreeNodeGet(b *testing.B) {
+	root := &treeNode{name: "/"}
+	for _, route := range benchRoutes {
+		root.Put(route)
+	}
+	params := make(Params, 0, 4)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, path := range benchPaths {
+			params = params[:0]
+			root.Get(path, &params)
+		}
+	}
+}
+
+func BenchmarkSegmentTreeNodeGet(b *testing.B) {
+	root := &segmentTreeNode{name: "/"}
+	for _, route := range benchRoutes {
+		root.Put(route)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, path := range benchPaths {
+			root.Get(path)
+		}
+	}
+}
+
+//原先按 / 切分路径的前缀树实现  仅用于基准测试对比
+type segmentTreeNode struct {
+	name       string
+	kind       nodeKind
+	children   []*segmentTreeNode
+	routerName string
+	isEnd      bool
+}
+
+func (t *segmentTreeNode) Put(path string) {
+	strs := strings.Split(path, "/")
+	for index, name := range strs {
+		if index == 0 {
+			continue
+		}
+		kind := segmentKind(name)
+		var child *segmentTreeNode
+		for _, node := range t.children {
+			if node.name == name {
+				child = node
+				break
+			}
+		}
+		if child == nil {
+			child = &segmentTreeNode{name: name, kind: kind}
+			i := len(t.children)
+			for i > 0 && t.children[i-1].kind > child.kind {
+				i--
+			}
+			t.children = append(t.children, nil)
+			copy(t.children[i+1:], t.children[i:])
+			t.children[i] = child
+		}
+		t = child
+	}
+	t.isEnd = true
+	t.routerName = path
+}
+
+func (t *segmentTreeNode) Get(path string) (*segmentTreeNode, Params) {
+	strs := strings.Split(path, "/")
+	if len(strs) < 2 {
+		return nil, nil
+	}
+	return t.match(strs[1:], nil)
+}
+
+func (t *segmentTreeNode) match(segs []string, params Params) (*segmentTreeNode, Params) {
+	if len(segs) == 0 {
+		if t.isEnd {
+			return t, params
+		}
+		return nil, nil
+	}
+	name := segs[0]
+	for _, node := range t.children {
+		switch node.kind {
+		case staticKind:
+			if node.name != name {
+				continue
+			}
+			if n, ps := node.match(segs[1:], params); n != nil {
+				return n, ps
+			}
+		case paramKind, wildcardKind:
+			if name == "" {
+				continue
+			}
+			key := wildcardKey
+			if node.kind == paramKind {
+				key = node.name[1:]
+			}
+			if n, ps := node.match(segs[1:], append(params, Param{Key: key, Value: name})); n != nil {
+				return n, ps
+			}
+		case catchAllKind:
+			if node.isEnd {
+				return node, append(params, Param{Key: catchAllKey, Value: strings.Join(segs, "/")})
+			}
+		}
+	}
+	return nil, nil
+}
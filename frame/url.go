@@ -0,0 +1,97 @@
+package frame
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+//为路由命名   g.Get("/detail/:id", handler).Name("user.detail")
+//名称在整个Engine中唯一，重复命名会panic
+func (rt *Route) Name(name string) *Route {
+	r := rt.group.router
+	if r.namedRoutes == nil {
+		r.namedRoutes = make(map[string]*Route)
+	}
+	if old, ok := r.namedRoutes[name]; ok && old != rt {
+		panic(fmt.Sprintf("路由名称 %s 已被 %s %s 使用", name, old.method, old.path))
+	}
+	rt.routeName = name
+	r.namedRoutes[name] = rt
+	return rt
+}
+
+//按路由名称生成URL   params为成对的key value
+//key与路由中的 :param * ** 对应时填充到路径中，其余的作为查询参数
+//e.URL("user.detail", "id", 1, "tab", "info") 返回 /user/detail/1?tab=info
+func (e *Engine) URL(name string, params ...any) (string, error) {
+	rt, ok := e.namedRoutes[name]
+	if !ok {
+		return "", fmt.Errorf("route %s not found", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("route %s: params must be key value pairs", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	keys := make([]string, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("route %s: param key %v must be a string", name, params[i])
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	var sb strings.Builder
+	used := make(map[string]bool)
+	segs := strings.Split(rt.path, "/")
+	for i, seg := range segs {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		kind := segmentKind(seg)
+		if kind == staticKind {
+			sb.WriteString(seg)
+			continue
+		}
+		key := seg
+		var constraint *paramConstraint
+		if kind == paramKind {
+			key, constraint = parseParamSegment(rt.path, seg)
+		}
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("route %s: missing param %s", name, key)
+		}
+		//不满足约束的值生成的url无法匹配到该路由
+		if constraint != nil && !constraint.match(value) {
+			return "", fmt.Errorf("route %s: param %s=%q does not match <%s>", name, key, value, constraint)
+		}
+		used[key] = true
+		if kind == catchAllKind {
+			//** 中的 / 保留
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			sb.WriteString(strings.Join(parts, "/"))
+		} else {
+			sb.WriteString(url.PathEscape(value))
+		}
+	}
+
+	query := url.Values{}
+	for _, key := range keys {
+		if !used[key] {
+			query.Set(key, values[key])
+		}
+	}
+	if len(query) > 0 {
+		sb.WriteByte('?')
+		sb.WriteString(query.Encode())
+	}
+	return sb.String(), nil
+}
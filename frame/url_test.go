@@ -0,0 +1,65 @@
+package frame
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestEngineURL(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/detail/:id", func(ctx *Context) {}).Name("user.detail")
+	g.Get("/static/**", func(ctx *Context) {}).Name("user.static")
+	g.Get("/order/:id<int>", func(ctx *Context) {}).Name("user.order")
+	g.Get("/tag/:slug<[a-z]+>", func(ctx *Context) {}).Name("user.tag")
+
+	tests := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"user.detail", []any{"id", 1}, "/user/detail/1"},
+		{"user.detail", []any{"id", "a b", "tab", "info&more"}, "/user/detail/a%20b?tab=info%26more"},
+		{"user.static", []any{"**", "css/a.css"}, "/user/static/css/a.css"},
+		{"user.order", []any{"id", 12}, "/user/order/12"},
+		{"user.tag", []any{"slug", "go"}, "/user/tag/go"},
+	}
+	for _, tt := range tests {
+		got, err := engine.URL(tt.name, tt.params...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q", tt.name, tt.params, got, err, tt.want)
+		}
+	}
+	if _, err := engine.URL("user.detail"); err == nil {
+		t.Error("expected error for missing param")
+	}
+	//不满足参数约束时返回错误
+	if _, err := engine.URL("user.order", "id", "abc"); err == nil {
+		t.Error("expected error for param not matching <int>")
+	}
+	if _, err := engine.URL("user.tag", "slug", "Go"); err == nil {
+		t.Error("expected error for param not matching <[a-z]+>")
+	}
+	if _, err := engine.URL("none"); err == nil {
+		t.Error("expected error for unknown route")
+	}
+
+	tpl := template.Must(template.New("").Funcs(engine.funcMap).Parse(`{{url "user.detail" "id" 7}}`))
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, nil); err != nil || buf.String() != "/user/detail/7" {
+		t.Errorf("template url = %q, %v", buf.String(), err)
+	}
+}
+
+func TestRouteNameDuplicate(t *testing.T) {
+	engine := New()
+	g := engine.Group("user")
+	g.Get("/a", func(ctx *Context) {}).Name("dup")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for duplicate route name")
+		}
+	}()
+	g.Get("/b", func(ctx *Context) {}).Name("dup")
+}
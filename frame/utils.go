@@ -1,6 +1,7 @@
 package frame
 
 import (
+	"path"
 	"strings"
 	"unicode"
 	"unsafe"
@@ -16,6 +17,30 @@ func SubStringLast(str, substr string) string {
 	return str[index+len(substr):]
 }
 
+//拼接路由组前缀与路由   joinPaths("/user", "/get") 返回 /user/get
+func joinPaths(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	return strings.TrimSuffix(basePath, "/") + "/" + strings.TrimPrefix(relativePath, "/")
+}
+
+//清理路径  合并多余的 /，处理 . 和 ..，保留末尾的 /
+//cleanPath("/user//get/../info/") 返回 /user/info/
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
 //判断是否是ASCII里的字符
 func isASCII(s string) bool {
 	for i := 0; i < len(s); i++ {